@@ -16,29 +16,183 @@ functions fill is some gaps in the Go language and native libraries.  They make
 it easy to perform the things that should be simple in Go but aren't for various
 reasons.  The functions cover the following areas:
   - File System
+  - Environment
   - Arrays
+  - Bytes
   - Maps
   - Strings
+  - Numbers
+  - Random Values
+  - Functions
+  - Errors
   - Reflection
 */
 package module
 
 import (
+	ord "cmp"
+	ran "crypto/rand"
+	csv "encoding/csv"
+	hex "encoding/hex"
+	jsn "encoding/json"
 	fmt "fmt"
+	nor "golang.org/x/text/unicode/norm"
+	iox "io"
+	mat "math"
 	cmp "math/cmplx"
 	osx "os"
+	pth "path"
+	fil "path/filepath"
 	ref "reflect"
 	run "runtime"
 	sor "sort"
 	stc "strconv"
 	sts "strings"
+	syn "sync"
+	tim "time"
 	uni "unicode"
+	utf "unicode/utf8"
 )
 
+// GLOBAL TYPES
+
+/*
+FormatOptions defines the options that control how the FormatWithOptions
+function formats a value.  The zero value formats a value exactly the same way
+that the Format function does.
+*/
+type FormatOptions struct {
+	SortFields   bool   // Format structure fields alphabetically by name.
+	PolarComplex bool   // Format complex numbers as amplitude∠phase.
+	Indentation  string // The indentation per nesting level (default "    ").
+
+	// Format bytes in the printable ASCII range as quoted characters.
+	PrintableBytes bool
+
+	// Prefix promoted structure field names with their embedded type names.
+	ShowEmbedding bool
+
+	// Suffix each non-nil pointer with its memory address (e.g. "@0xc000010000")
+	// so that aliased values can be identified.  The default omits addresses
+	// to keep the output deterministic.
+	ShowAddresses bool
+
+	// Format class instances having exactly one getter method as the value
+	// returned by the getter on a single line.
+	InlineGetters bool
+
+	// Append the result of calling the String() method on each value that
+	// implements the fmt.Stringer interface as a trailing comment.
+	AnnotateStringer bool
+
+	// Group the keys of maps containing keys of different kinds under a
+	// comment naming each kind.
+	GroupMapKeys bool
+
+	// Format nullable wrapper structures (e.g. sql.NullString) having a bool
+	// "Valid" field and exactly one other exported field as either the value
+	// of the other field or "<null>".
+	DetectNullable bool
+
+	// Render values using this function when it returns true.  Otherwise the
+	// values are formatted normally.
+	Renderer func(value any) (string, bool)
+
+	// Format small arrays and maps on a single line.  A zero size or width
+	// means that the default compact size or width is used.
+	CompactArrays bool
+	CompactSize   uint // The maximum number of elements on a single line.
+	CompactWidth  uint // The maximum width of the elements on a single line.
+}
+
 // GLOBAL FUNCTIONS
 
 // File System
 
+/*
+JoinPath joins the specified path elements using a single "/" separator between
+each pair of elements regardless of any leading or trailing slashes on each
+element.  Empty elements are ignored and any duplicate slashes are collapsed.
+The resulting path is absolute only if the first non-empty element is absolute.
+*/
+func JoinPath(
+	elements ...string,
+) string {
+	var isAbsolute bool
+	var components []string
+	for _, element := range elements {
+		if len(element) == 0 {
+			continue
+		}
+		if len(components) == 0 && sts.HasPrefix(element, "/") {
+			isAbsolute = true
+		}
+		for _, component := range sts.Split(element, "/") {
+			if len(component) > 0 {
+				components = append(components, component)
+			}
+		}
+	}
+	var path = sts.Join(components, "/")
+	if isAbsolute {
+		path = "/" + path
+	}
+	return path
+}
+
+/*
+BaseName returns the final component of the specified "/" delimited path.  Any
+trailing slashes are ignored so the base name of "a/b/" is "b".  The base name
+of the root path "/" is "/".
+*/
+func BaseName(
+	path string,
+) string {
+	return pth.Base(path)
+}
+
+/*
+DirName returns the parent directory of the specified "/" delimited path.  Any
+trailing slashes are ignored so the directory name of "a/b/" is "a".  The
+directory name of the root path "/" is "/" and the directory name of a path
+without any slashes is ".".
+*/
+func DirName(
+	path string,
+) string {
+	return pth.Dir(pth.Clean(path))
+}
+
+/*
+FileExtension returns the extension of the final component of the specified
+path including the leading dot (e.g. ".go").  Only the last extension is
+returned for names containing multiple dots.  An empty string is returned if
+there is no extension, including for hidden files like ".profile".
+*/
+func FileExtension(
+	path string,
+) string {
+	var name = BaseName(path)
+	var extension = pth.Ext(name)
+	if extension == name {
+		// This is a hidden file without an extension.
+		extension = ""
+	}
+	return extension
+}
+
+/*
+StripExtension returns the specified path without the extension of its final
+component as determined by the FileExtension function.
+*/
+func StripExtension(
+	path string,
+) string {
+	path = sts.TrimRight(path, "/")
+	var extension = FileExtension(path)
+	return sts.TrimSuffix(path, extension)
+}
+
 /*
 PathExists checks whether or not the specified file system path is defined.  An
 empty string or a nil pointer is considered to be undefined.
@@ -46,14 +200,29 @@ empty string or a nil pointer is considered to be undefined.
 func PathExists(
 	path string,
 ) bool {
+	var exists, err = PathExistsSafely(path)
+	if err != nil {
+		panic(err)
+	}
+	return exists
+}
+
+/*
+PathExistsSafely checks whether or not the specified file system path is
+defined.  Unlike the PathExists function it returns any error (e.g. a permission
+error) that prevents the check rather than panicking.
+*/
+func PathExistsSafely(
+	path string,
+) (bool, error) {
 	var _, err = osx.Stat(path)
 	if err == nil {
-		return true
+		return true, nil
 	}
 	if osx.IsNotExist(err) {
-		return false
+		return false, nil
 	}
-	panic(err)
+	return false, err
 }
 
 /*
@@ -63,12 +232,22 @@ file system path.
 func RemovePath(
 	path string,
 ) {
-	var err = osx.RemoveAll(path)
+	var err = RemovePathSafely(path)
 	if err != nil {
 		panic(err)
 	}
 }
 
+/*
+RemovePathSafely recursively removes all directories and files found in the
+specified file system path.  It returns any error rather than panicking.
+*/
+func RemovePathSafely(
+	path string,
+) error {
+	return osx.RemoveAll(path)
+}
+
 /*
 MakeDirectory creates all directories in the specified file system directory
 path.
@@ -76,10 +255,67 @@ path.
 func MakeDirectory(
 	directory string,
 ) {
-	var err = osx.MkdirAll(directory, 0755)
+	var err = MakeDirectorySafely(directory)
+	if err != nil {
+		panic(err)
+	}
+}
+
+/*
+MakeDirectorySafely creates all directories in the specified file system
+directory path.  It returns any error rather than panicking.
+*/
+func MakeDirectorySafely(
+	directory string,
+) error {
+	return osx.MkdirAll(directory, 0755)
+}
+
+/*
+EnsureDirectory creates all directories in the specified file system directory
+path if the directory does not already exist.  It returns true if the directory
+was created and false if it already existed.  It panics if a file that is not a
+directory already exists at the path.
+*/
+func EnsureDirectory(
+	directory string,
+) bool {
+	var created, err = EnsureDirectorySafely(directory)
 	if err != nil {
 		panic(err)
 	}
+	return created
+}
+
+/*
+EnsureDirectorySafely creates all directories in the specified file system
+directory path if the directory does not already exist.  It returns true if the
+directory was created and false if it already existed.  It returns any error,
+including when a file that is not a directory already exists at the path,
+rather than panicking.
+*/
+func EnsureDirectorySafely(
+	directory string,
+) (bool, error) {
+	var info, err = osx.Stat(directory)
+	if err == nil {
+		if !info.IsDir() {
+			err = fmt.Errorf(
+				"Attempted to ensure a directory where a file exists: %v",
+				directory,
+			)
+			return false, err
+		}
+		return false, nil
+	}
+	if !osx.IsNotExist(err) {
+		return false, err
+	}
+	err = MakeDirectorySafely(directory)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 /*
@@ -89,16 +325,168 @@ specified file system directory path.
 func RemakeDirectory(
 	directory string,
 ) {
+	var err = RemakeDirectorySafely(directory)
+	if err != nil {
+		panic(err)
+	}
+}
+
+/*
+RemakeDirectorySafely recursively removes all files and subdirectories from the
+specified file system directory path.  It returns any error rather than
+panicking.
+*/
+func RemakeDirectorySafely(
+	directory string,
+) error {
 	var err = osx.RemoveAll(directory)
+	if err != nil {
+		return err
+	}
+	return osx.MkdirAll(directory, 0755)
+}
+
+/*
+CopyFile copies the specified source file in the file system to the specified
+destination file, preserving the file mode bits.  Any existing destination file
+is overwritten but it panics if the destination is a directory.  A symbolic link
+is copied as a symbolic link to the same target rather than being followed.
+*/
+func CopyFile(
+	source string,
+	destination string,
+) {
+	var err = CopyFileSafely(source, destination)
 	if err != nil {
 		panic(err)
 	}
-	err = osx.MkdirAll(directory, 0755)
+}
+
+/*
+CopyFileSafely copies the specified source file in the file system to the
+specified destination file as described for the CopyFile function.  It returns
+any error rather than panicking.
+*/
+func CopyFileSafely(
+	source string,
+	destination string,
+) error {
+	var info, err = osx.Lstat(source)
+	if err != nil {
+		return err
+	}
+	var target string
+	var bytes []byte
+	var isSymlink = info.Mode()&osx.ModeSymlink != 0
+	if isSymlink {
+		target, err = osx.Readlink(source)
+	} else {
+		bytes, err = osx.ReadFile(source)
+	}
+	if err != nil {
+		return err
+	}
+	// Remove any existing destination file so that a symbolic link is never
+	// followed when overwriting it.  An existing directory is never removed.
+	var existing osx.FileInfo
+	existing, err = osx.Lstat(destination)
+	if err == nil && existing.IsDir() {
+		err = fmt.Errorf(
+			"Attempted to copy a file over a directory: %v",
+			destination,
+		)
+		return err
+	}
+	err = osx.Remove(destination)
+	if err != nil && !osx.IsNotExist(err) {
+		return err
+	}
+	if isSymlink {
+		return osx.Symlink(target, destination)
+	}
+	var mode = info.Mode().Perm()
+	err = osx.WriteFile(destination, bytes, mode)
+	if err != nil {
+		return err
+	}
+	// The umask may have masked some of the mode bits on creation.
+	return osx.Chmod(destination, mode)
+}
+
+/*
+CopyDirectory recursively copies the files and subdirectories in the specified
+source directory into the specified destination directory, creating the
+destination directory if it does not already exist.  Each file is copied using
+the CopyFile function so file mode bits are preserved, existing files are
+overwritten and symbolic links are copied rather than followed.  It panics if
+the destination directory is inside the source directory.
+*/
+func CopyDirectory(
+	source string,
+	destination string,
+) {
+	var err = CopyDirectorySafely(source, destination)
 	if err != nil {
 		panic(err)
 	}
 }
 
+/*
+CopyDirectorySafely recursively copies the files and subdirectories in the
+specified source directory into the specified destination directory as
+described for the CopyDirectory function.  It returns any error rather than
+panicking.
+*/
+func CopyDirectorySafely(
+	source string,
+	destination string,
+) error {
+	var info, err = osx.Stat(source)
+	if err != nil {
+		return err
+	}
+	var sourcePath, destinationPath string
+	sourcePath, err = fil.Abs(source)
+	if err != nil {
+		return err
+	}
+	destinationPath, err = fil.Abs(destination)
+	if err != nil {
+		return err
+	}
+	if destinationPath == sourcePath ||
+		sts.HasPrefix(destinationPath, sts.TrimSuffix(sourcePath, "/")+"/") {
+		err = fmt.Errorf(
+			"Attempted to copy a directory into itself: %v",
+			destination,
+		)
+		return err
+	}
+	err = MakeDirectorySafely(destination)
+	if err != nil {
+		return err
+	}
+	var entries []osx.DirEntry
+	entries, err = osx.ReadDir(source)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		var sourceChild = JoinPath(source, entry.Name())
+		var destinationChild = JoinPath(destination, entry.Name())
+		if entry.IsDir() {
+			err = CopyDirectorySafely(sourceChild, destinationChild)
+		} else {
+			err = CopyFileSafely(sourceChild, destinationChild)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	// The mode bits are copied last in case they prevent writing the entries.
+	return osx.Chmod(destination, info.Mode().Perm())
+}
+
 /*
 ReadFile returns the contents of the specified file from the file system as a
 string.
@@ -106,14 +494,28 @@ string.
 func ReadFile(
 	filename string,
 ) string {
-	var bytes, err = osx.ReadFile(filename)
+	var source, err = ReadFileSafely(filename)
 	if err != nil {
 		panic(err)
 	}
-	var source = string(bytes)
 	return source
 }
 
+/*
+ReadFileSafely returns the contents of the specified file from the file system
+as a string.  It returns any error rather than panicking.
+*/
+func ReadFileSafely(
+	filename string,
+) (string, error) {
+	var bytes, err = osx.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	var source = string(bytes)
+	return source, nil
+}
+
 /*
 WriteFile writes the specified source string as the contents of the specified
 file in the file system.
@@ -122,111 +524,1249 @@ func WriteFile(
 	filename string,
 	source string,
 ) {
-	var bytes = []byte(source)
-	var err = osx.WriteFile(filename, bytes, 0644)
+	var err = WriteFileSafely(filename, source)
 	if err != nil {
 		panic(err)
 	}
 }
 
-// Arrays
-
 /*
-CopyArray[V any] returns a copy of the specified array with the same size and
-elements as the specified array.  The result is not a deep copy.
+WriteFileSafely writes the specified source string as the contents of the
+specified file in the file system.  It returns any error rather than panicking.
 */
-func CopyArray[V any](
-	array []V,
-) []V {
-	var size = len(array)
-	var duplicate = make([]V, size)
-	copy(duplicate, array)
-	return duplicate
+func WriteFileSafely(
+	filename string,
+	source string,
+) error {
+	var bytes = []byte(source)
+	return osx.WriteFile(filename, bytes, 0644)
 }
 
 /*
-ArraysAreEqual[V comparable] determines whether or not the specified arrays have
-the same elements.
+WriteFileForced writes the specified source string as the contents of the
+specified file in the file system, first creating any missing directories in
+the path to the file.
 */
-func ArraysAreEqual[V comparable](
-	first []V,
-	second []V,
-) bool {
-	if len(first) != len(second) {
-		return false
-	}
-	for index, value := range first {
-		if value != second[index] {
-			return false
-		}
+func WriteFileForced(
+	filename string,
+	source string,
+) {
+	var err = WriteFileForcedSafely(filename, source)
+	if err != nil {
+		panic(err)
 	}
-	return true
 }
 
-// Maps
-
 /*
-CopyMap[K comparable, V any] returns a copy of the specified map with the same
-size and key-value pairs as the specified map.  The result is not a deep copy.
+WriteFileForcedSafely writes the specified source string as the contents of the
+specified file in the file system, first creating any missing directories in
+the path to the file.  It returns any error rather than panicking.
 */
-func CopyMap[K comparable, V any](
-	map_ map[K]V,
-) map[K]V {
-	var duplicate = make(map[K]V)
-	for key, value := range map_ {
-		duplicate[key] = value
+func WriteFileForcedSafely(
+	filename string,
+	source string,
+) error {
+	var err = MakeDirectorySafely(DirName(filename))
+	if err != nil {
+		return err
 	}
-	return duplicate
+	return WriteFileSafely(filename, source)
 }
 
 /*
-MapsAreEqual[K comparable, V comparable] determines whether or not the specified
-maps have the same key-value pairs.  This function is deterministic even though
-Go maps are not.
+WriteFileAtomically writes the specified source string as the contents of the
+specified file in the file system so that readers always see either the previous
+or the new contents in full.  The source is written to a temporary file in the
+same directory, flushed to storage and then renamed over the specified file.
+The temporary file is removed if any step fails.
 */
-func MapsAreEqual[K comparable, V comparable](
-	first map[K]V,
-	second map[K]V,
-) bool {
-	if len(first) != len(second) {
-		return false
-	}
-	for key, value := range first {
-		if second[key] != value {
-			return false
-		}
+func WriteFileAtomically(
+	filename string,
+	source string,
+) {
+	var err = WriteFileAtomicallySafely(filename, source)
+	if err != nil {
+		panic(err)
 	}
-	return true
 }
 
-// Strings
-
 /*
-MakeAllCaps modifies the specified mixed case string into a corresponding all
-uppercase string using "_"s to separate the words found in the mixed case
-string.
+WriteFileAtomicallySafely writes the specified source string as the contents of
+the specified file in the file system as described for the WriteFileAtomically
+function.  It returns any error rather than panicking.
 */
-func MakeAllCaps(
-	mixedCase string,
-) string {
-	var allCaps sts.Builder
-	var foundLower bool
-	for _, r := range mixedCase {
-		switch {
-		case uni.IsLower(r):
-			foundLower = true
-			allCaps.WriteRune(uni.ToUpper(r))
-		case uni.IsUpper(r):
-			if foundLower {
-				allCaps.WriteString("_")
-				foundLower = false
-			}
-			allCaps.WriteRune(r)
-		default:
-			allCaps.WriteRune(r)
+func WriteFileAtomicallySafely(
+	filename string,
+	source string,
+) (err error) {
+	// The temporary file must be in the same directory so that the rename
+	// stays on the same file system and is therefore atomic.
+	var directory = DirName(filename)
+	var pattern = "." + BaseName(filename) + ".*.tmp"
+	var file *osx.File
+	file, err = osx.CreateTemp(directory, pattern)
+	if err != nil {
+		return err
+	}
+	var temporary = file.Name()
+	defer func() {
+		if err != nil {
+			file.Close()
+			osx.Remove(temporary)
 		}
+	}()
+	_, err = file.WriteString(source)
+	if err != nil {
+		return err
 	}
-	return allCaps.String()
+	err = file.Chmod(0644)
+	if err != nil {
+		return err
+	}
+	err = file.Sync()
+	if err != nil {
+		return err
+	}
+	err = file.Close()
+	if err != nil {
+		return err
+	}
+	return osx.Rename(temporary, filename)
+}
+
+/*
+AppendFile appends the specified source string to the end of the specified file
+in the file system, creating the file if it does not already exist.  It panics
+if the directory containing the file does not exist.
+*/
+func AppendFile(
+	filename string,
+	source string,
+) {
+	var err = AppendFileSafely(filename, source)
+	if err != nil {
+		panic(err)
+	}
+}
+
+/*
+AppendFileSafely appends the specified source string to the end of the specified
+file in the file system, creating the file if it does not already exist.  It
+returns any error, including when the directory containing the file does not
+exist, rather than panicking.
+*/
+func AppendFileSafely(
+	filename string,
+	source string,
+) error {
+	var directory = DirName(filename)
+	var exists, err = PathExistsSafely(directory)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = fmt.Errorf(
+			"Attempted to append to a file in a missing directory: %v",
+			directory,
+		)
+		return err
+	}
+	var file *osx.File
+	file, err = osx.OpenFile(
+		filename,
+		osx.O_APPEND|osx.O_CREATE|osx.O_WRONLY,
+		0644,
+	)
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteString(source)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+/*
+ReadJSON reads the contents of the specified JSON file from the file system and
+unmarshals it into the specified target, which must be a pointer.
+*/
+func ReadJSON(
+	filename string,
+	target any,
+) {
+	var err = ReadJSONSafely(filename, target)
+	if err != nil {
+		panic(err)
+	}
+}
+
+/*
+ReadJSONSafely reads the contents of the specified JSON file from the file
+system and unmarshals it into the specified target, which must be a pointer.  It
+returns any error rather than panicking.
+*/
+func ReadJSONSafely(
+	filename string,
+	target any,
+) error {
+	var source, err = ReadFileSafely(filename)
+	if err != nil {
+		return err
+	}
+	return jsn.Unmarshal([]byte(source), target)
+}
+
+/*
+WriteJSON marshals the specified value into indented JSON and writes it as the
+contents of the specified file in the file system.
+*/
+func WriteJSON(
+	filename string,
+	value any,
+) {
+	var err = WriteJSONSafely(filename, value)
+	if err != nil {
+		panic(err)
+	}
+}
+
+/*
+WriteJSONSafely marshals the specified value into indented JSON and writes it as
+the contents of the specified file in the file system.  It returns any error
+rather than panicking.
+*/
+func WriteJSONSafely(
+	filename string,
+	value any,
+) error {
+	var bytes, err = jsn.MarshalIndent(value, "", "    ")
+	if err != nil {
+		return err
+	}
+	var source = string(bytes) + "\n"
+	return WriteFileSafely(filename, source)
+}
+
+/*
+ReadCSV reads the contents of the specified CSV file from the file system and
+returns its rows, each as an array of string fields.
+*/
+func ReadCSV(
+	filename string,
+) [][]string {
+	var rows, err = ReadCSVSafely(filename)
+	if err != nil {
+		panic(err)
+	}
+	return rows
+}
+
+/*
+ReadCSVSafely reads the contents of the specified CSV file from the file system
+and returns its rows, each as an array of string fields.  It returns any error
+rather than panicking.
+*/
+func ReadCSVSafely(
+	filename string,
+) ([][]string, error) {
+	var source, err = ReadFileSafely(filename)
+	if err != nil {
+		return nil, err
+	}
+	var reader = csv.NewReader(sts.NewReader(source))
+	return reader.ReadAll()
+}
+
+/*
+WriteCSV writes the specified rows of string fields as the contents of the
+specified CSV file in the file system.  Fields are quoted as needed.
+*/
+func WriteCSV(
+	filename string,
+	rows [][]string,
+) {
+	var err = WriteCSVSafely(filename, rows)
+	if err != nil {
+		panic(err)
+	}
+}
+
+/*
+WriteCSVSafely writes the specified rows of string fields as the contents of the
+specified CSV file in the file system.  Fields are quoted as needed.  It returns
+any error rather than panicking.
+*/
+func WriteCSVSafely(
+	filename string,
+	rows [][]string,
+) error {
+	var builder sts.Builder
+	var writer = csv.NewWriter(&builder)
+	var err = writer.WriteAll(rows)
+	if err != nil {
+		return err
+	}
+	return WriteFileSafely(filename, builder.String())
+}
+
+// Environment
+
+/*
+GetEnvironment returns the value of the specified environment variable if it is
+defined, otherwise it returns the specified fallback value.  An environment
+variable that is set to an empty string is treated as undefined.
+*/
+func GetEnvironment(
+	name string,
+	fallback string,
+) string {
+	var value = osx.Getenv(name)
+	if !IsDefined(value) {
+		value = fallback
+	}
+	return value
+}
+
+/*
+RequireEnvironment returns the value of the specified environment variable.  It
+panics if the environment variable is not defined.
+*/
+func RequireEnvironment(
+	name string,
+) string {
+	var value, ok = osx.LookupEnv(name)
+	if !ok {
+		var message = fmt.Sprintf(
+			"Attempted to access an undefined environment variable: %v",
+			name,
+		)
+		panic(message)
+	}
+	return value
+}
+
+// Arrays
+
+/*
+CopyArray[V any] returns a copy of the specified array with the same size and
+elements as the specified array.  The result is not a deep copy.
+*/
+func CopyArray[V any](
+	array []V,
+) []V {
+	var size = len(array)
+	var duplicate = make([]V, size)
+	copy(duplicate, array)
+	return duplicate
+}
+
+/*
+ArraysAreEqual[V comparable] determines whether or not the specified arrays have
+the same elements.
+*/
+func ArraysAreEqual[V comparable](
+	first []V,
+	second []V,
+) bool {
+	if len(first) != len(second) {
+		return false
+	}
+	for index, value := range first {
+		if value != second[index] {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+ArraysAreEquivalent[V comparable] determines whether or not the specified arrays
+have the same elements with the same multiplicities, regardless of their order.
+*/
+func ArraysAreEquivalent[V comparable](
+	first []V,
+	second []V,
+) bool {
+	if len(first) != len(second) {
+		return false
+	}
+	var counts = make(map[V]int, len(first))
+	for _, value := range first {
+		counts[value]++
+	}
+	for _, value := range second {
+		var count = counts[value]
+		if count == 0 {
+			return false
+		}
+		counts[value] = count - 1
+	}
+	return true
+}
+
+/*
+BinarySearch[V cmp.Ordered] searches the specified ascending array for the
+specified target value.  It returns the zero-based index of the first element
+that is equal to the target and true if the target is found.  Otherwise it
+returns the index at which the target would be inserted to keep the array in
+ascending order and false.
+*/
+func BinarySearch[V ord.Ordered](
+	array []V,
+	target V,
+) (index int, found bool) {
+	var size = len(array)
+	index = sor.Search(size, func(index int) bool {
+		return array[index] >= target
+	})
+	found = index < size && array[index] == target
+	return index, found
+}
+
+/*
+ChannelToSlice[V any] receives each value from the specified channel until the
+channel is closed and returns the values in the order they were received.
+*/
+func ChannelToSlice[V any](
+	channel <-chan V,
+) []V {
+	var array = []V{}
+	for value := range channel {
+		array = append(array, value)
+	}
+	return array
+}
+
+/*
+CompactArray[V any] returns a new array containing only the elements of the
+specified array that are defined as determined by the IsDefined function (e.g.
+omitting nil pointers, empty strings and nil arrays).
+*/
+func CompactArray[V any](
+	array []V,
+) []V {
+	var compacted = make([]V, 0, len(array))
+	for _, value := range array {
+		if IsDefined(value) {
+			compacted = append(compacted, value)
+		}
+	}
+	return compacted
+}
+
+/*
+DistinctBy[V any, K comparable] returns a new array containing the elements of
+the specified array in their original order, omitting each element whose key,
+as projected by the specified function, matches that of an earlier element.
+*/
+func DistinctBy[V any, K comparable](
+	array []V,
+	keyOf func(V) K,
+) []V {
+	var seen = make(map[K]bool, len(array))
+	var distinct = make([]V, 0, len(array))
+	for _, value := range array {
+		var key = keyOf(value)
+		if !seen[key] {
+			seen[key] = true
+			distinct = append(distinct, value)
+		}
+	}
+	return distinct
+}
+
+/*
+FlattenArray[V any] returns a new array containing the elements of each of the
+inner arrays of the specified nested array concatenated in order.
+*/
+func FlattenArray[V any](
+	nested [][]V,
+) []V {
+	var size int
+	for _, inner := range nested {
+		size += len(inner)
+	}
+	var flattened = make([]V, 0, size)
+	for _, inner := range nested {
+		flattened = append(flattened, inner...)
+	}
+	return flattened
+}
+
+/*
+ForEach[V any] calls the specified action function on each element of the
+specified array in order, passing in the zero-based index and value of the
+element.
+*/
+func ForEach[V any](
+	array []V,
+	action func(index uint, value V),
+) {
+	for index, value := range array {
+		action(uint(index), value)
+	}
+}
+
+/*
+InsertSorted[V cmp.Ordered] returns a new array containing the elements of the
+specified ascending array with the specified value inserted at the position that
+keeps the new array in ascending order.  The value is inserted after any equal
+elements.  A binary search is used to find the insertion point.
+*/
+func InsertSorted[V ord.Ordered](
+	array []V,
+	value V,
+) []V {
+	var size = len(array)
+	var index = sor.Search(size, func(index int) bool {
+		return array[index] > value
+	})
+	var inserted = make([]V, size+1)
+	copy(inserted, array[:index])
+	inserted[index] = value
+	copy(inserted[index+1:], array[index:])
+	return inserted
+}
+
+/*
+LastIndexOfValue[V comparable] returns the zero-based index of the last
+occurrence of the specified value in the specified array.  It returns -1 if the
+value does not occur in the array.
+*/
+func LastIndexOfValue[V comparable](
+	array []V,
+	value V,
+) int {
+	for index := len(array) - 1; index >= 0; index-- {
+		if array[index] == value {
+			return index
+		}
+	}
+	return -1
+}
+
+/*
+ReverseArrayInPlace[V any] reverses the order of the elements in the specified
+array without allocating a new array.
+*/
+func ReverseArrayInPlace[V any](
+	array []V,
+) {
+	var last = len(array) - 1
+	for index := 0; index < last-index; index++ {
+		array[index], array[last-index] = array[last-index], array[index]
+	}
+}
+
+/*
+RotateArray[V any] returns a new array containing the elements of the specified
+array rotated left by the specified number of positions.  A negative number of
+positions rotates the elements right.  The number of positions is taken modulo
+the size of the array.
+*/
+func RotateArray[V any](
+	array []V,
+	positions int,
+) []V {
+	var size = len(array)
+	var rotated = make([]V, size)
+	if size == 0 {
+		return rotated
+	}
+	positions %= size
+	if positions < 0 {
+		positions += size
+	}
+	copy(rotated, array[positions:])
+	copy(rotated[size-positions:], array[:positions])
+	return rotated
+}
+
+/*
+SliceToChannel[V any] returns a closed channel that is buffered with the
+elements of the specified array in order.  Since the channel is buffered no
+goroutine is needed and none is leaked if the channel is not drained.
+*/
+func SliceToChannel[V any](
+	array []V,
+) <-chan V {
+	var channel = make(chan V, len(array))
+	for _, value := range array {
+		channel <- value
+	}
+	close(channel)
+	return channel
+}
+
+/*
+SortArrayByKey[V any, K cmp.Ordered] returns a new array containing the elements
+of the specified array sorted in ascending order by the key that the specified
+function projects from each element.  Elements with equal keys retain their
+original order.
+*/
+func SortArrayByKey[V any, K ord.Ordered](
+	array []V,
+	keyOf func(V) K,
+) []V {
+	var sorted = CopyArray(array)
+	sor.SliceStable(
+		sorted,
+		func(i, j int) bool {
+			return keyOf(sorted[i]) < keyOf(sorted[j])
+		},
+	)
+	return sorted
+}
+
+/*
+SwapValues[V any] swaps the two elements of the specified array that are at the
+specified relative ordinal positions.  The ordinals run from 1 to the size of the
+array for the first through last elements, and from -1 to the negative size of
+the array for the last through first elements.  It panics if either ordinal is
+zero or is out of range.
+*/
+func SwapValues[V any](
+	array []V,
+	firstOrdinal int,
+	secondOrdinal int,
+) {
+	var size = len(array)
+	var first = relativeToCardinal(firstOrdinal, size)
+	var second = relativeToCardinal(secondOrdinal, size)
+	array[first], array[second] = array[second], array[first]
+}
+
+// Bytes
+
+/*
+RotateLeftBytes returns a new byte array containing the bits of the specified
+byte array rotated left by the specified number of bits.  The byte array is
+treated as a single big-endian sequence of bits with the bits that are shifted
+out of the first byte being shifted into the last byte.
+*/
+func RotateLeftBytes(
+	bytes []byte,
+	bits uint,
+) []byte {
+	var size = uint(len(bytes))
+	var rotated = make([]byte, size)
+	if size == 0 {
+		return rotated
+	}
+	bits %= 8 * size
+	var offset = bits / 8
+	var shift = bits % 8
+	for index := uint(0); index < size; index++ {
+		var first = bytes[(index+offset)%size]
+		var second = bytes[(index+offset+1)%size]
+		rotated[index] = first<<shift | second>>(8-shift)
+	}
+	return rotated
+}
+
+/*
+XORBytes returns a new byte array containing the bitwise exclusive or (XOR) of
+the corresponding bytes in the specified byte arrays.  The byte arrays must be
+the same size.
+*/
+func XORBytes(
+	a []byte,
+	b []byte,
+) []byte {
+	if len(a) != len(b) {
+		var message = fmt.Sprintf(
+			"Attempted to XOR byte arrays of different sizes: %v and %v",
+			len(a),
+			len(b),
+		)
+		panic(message)
+	}
+	var result = make([]byte, len(a))
+	for index := range a {
+		result[index] = a[index] ^ b[index]
+	}
+	return result
+}
+
+// Maps
+
+/*
+CopyMap[K comparable, V any] returns a copy of the specified map with the same
+size and key-value pairs as the specified map.  The result is not a deep copy.
+*/
+func CopyMap[K comparable, V any](
+	map_ map[K]V,
+) map[K]V {
+	var duplicate = make(map[K]V)
+	for key, value := range map_ {
+		duplicate[key] = value
+	}
+	return duplicate
+}
+
+/*
+MapsAreEqual[K comparable, V comparable] determines whether or not the specified
+maps have the same key-value pairs.  This function is deterministic even though
+Go maps are not.
+*/
+func MapsAreEqual[K comparable, V comparable](
+	first map[K]V,
+	second map[K]V,
+) bool {
+	if len(first) != len(second) {
+		return false
+	}
+	for key, value := range first {
+		if second[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+MapsAreEqualIgnoring[K comparable, V comparable] determines whether or not the
+specified maps have the same key-value pairs when the key-value pairs with keys
+in the specified ignore list are disregarded.
+*/
+func MapsAreEqualIgnoring[K comparable, V comparable](
+	first map[K]V,
+	second map[K]V,
+	ignore []K,
+) bool {
+	var ignored = make(map[K]bool)
+	for _, key := range ignore {
+		ignored[key] = true
+	}
+	for key, value := range first {
+		if ignored[key] {
+			continue
+		}
+		var other, exists = second[key]
+		if !exists || other != value {
+			return false
+		}
+	}
+	for key := range second {
+		if ignored[key] {
+			continue
+		}
+		var _, exists = first[key]
+		if !exists {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+MapContainsKey[K comparable, V any] determines whether or not the specified map
+contains the specified key.
+*/
+func MapContainsKey[K comparable, V any](
+	map_ map[K]V,
+	key K,
+) bool {
+	var _, exists = map_[key]
+	return exists
+}
+
+/*
+MapContainsValue[K comparable, V comparable] determines whether or not the
+specified map contains the specified value for any of its keys.
+*/
+func MapContainsValue[K comparable, V comparable](
+	map_ map[K]V,
+	value V,
+) bool {
+	for _, candidate := range map_ {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+MapFilterKeys[K comparable, V any] returns a new map containing only the
+key-value pairs from the specified map whose keys satisfy the specified keep
+function.
+*/
+func MapFilterKeys[K comparable, V any](
+	map_ map[K]V,
+	keep func(K) bool,
+) map[K]V {
+	var filtered = make(map[K]V)
+	for key, value := range map_ {
+		if keep(key) {
+			filtered[key] = value
+		}
+	}
+	return filtered
+}
+
+/*
+MapFilterValues[K comparable, V any] returns a new map containing only the
+key-value pairs from the specified map whose values satisfy the specified keep
+function.
+*/
+func MapFilterValues[K comparable, V any](
+	map_ map[K]V,
+	keep func(V) bool,
+) map[K]V {
+	var filtered = make(map[K]V)
+	for key, value := range map_ {
+		if keep(value) {
+			filtered[key] = value
+		}
+	}
+	return filtered
+}
+
+/*
+MapIsSubset[K comparable, V comparable] determines whether or not each key-value
+pair in the first specified map is also in the second specified map.
+*/
+func MapIsSubset[K comparable, V comparable](
+	sub map[K]V,
+	super map[K]V,
+) bool {
+	if len(sub) > len(super) {
+		return false
+	}
+	for key, value := range sub {
+		var other, exists = super[key]
+		if !exists || other != value {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+DeepMergeMaps returns a new map containing the key-value pairs from the specified
+base map overlaid with those from the specified override map.  When both maps
+associate a key with a nested map[string]any value the nested maps are merged
+recursively, otherwise the override value replaces the base value.  Neither map
+is modified and any nested maps are copied rather than shared.
+*/
+func DeepMergeMaps(
+	base map[string]any,
+	override map[string]any,
+) map[string]any {
+	var merged = make(map[string]any, len(base)+len(override))
+	for key, value := range base {
+		var nested, isMap = value.(map[string]any)
+		if isMap {
+			value = DeepMergeMaps(nested, nil)
+		}
+		merged[key] = value
+	}
+	for key, value := range override {
+		var nested, isMap = value.(map[string]any)
+		if isMap {
+			var existing, _ = merged[key].(map[string]any)
+			value = DeepMergeMaps(existing, nested)
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+/*
+DiffMaps[K comparable, V comparable] compares the specified previous and current
+maps and returns the keys that were added to the current map, the keys that were
+removed from the previous map, and the keys whose values changed.  Each array of
+keys is sorted using the same criteria that the Format function uses to sort the
+keys of a map.
+*/
+func DiffMaps[K comparable, V comparable](
+	previous map[K]V,
+	current map[K]V,
+) (
+	added []K,
+	removed []K,
+	changed []K,
+) {
+	for key, value := range current {
+		var previousValue, exists = previous[key]
+		switch {
+		case !exists:
+			added = append(added, key)
+		case previousValue != value:
+			changed = append(changed, key)
+		}
+	}
+	for key := range previous {
+		var _, exists = current[key]
+		if !exists {
+			removed = append(removed, key)
+		}
+	}
+	sortValues(added)
+	sortValues(removed)
+	sortValues(changed)
+	return
+}
+
+/*
+GetFold[V any] returns the value associated with the specified key in the
+specified map when case is ignored, and whether or not such a key was found.  An
+exact match is preferred, otherwise the first matching key in sorted order is
+used.  Since each stored key must be folded and compared, a lookup takes O(n)
+time for a map containing n keys.
+*/
+func GetFold[V any](
+	map_ map[string]V,
+	key string,
+) (V, bool) {
+	var value, found = map_[key]
+	if found {
+		return value, found
+	}
+	for _, candidate := range sortedKeys(map_) {
+		if sts.EqualFold(candidate, key) {
+			return map_[candidate], true
+		}
+	}
+	return value, false
+}
+
+/*
+ReduceMap[K comparable, V any, A any] combines each key-value pair in the
+specified map with the accumulated result, starting with the specified initial
+value, using the specified reducer function.  The keys are visited in sorted
+order so that the result is reproducible.
+*/
+func ReduceMap[K comparable, V any, A any](
+	map_ map[K]V,
+	initial A,
+	reducer func(A, K, V) A,
+) A {
+	var result = initial
+	for _, key := range sortedKeys(map_) {
+		result = reducer(result, key, map_[key])
+	}
+	return result
+}
+
+/*
+TransformMap[K comparable, V any, W any] returns a new map containing the keys
+from the specified map, each associated with the result of calling the specified
+transform function on its key-value pair.
+*/
+func TransformMap[K comparable, V any, W any](
+	map_ map[K]V,
+	transform func(K, V) W,
+) map[K]W {
+	var result = make(map[K]W, len(map_))
+	for key, value := range map_ {
+		result[key] = transform(key, value)
+	}
+	return result
+}
+
+// Strings
+
+/*
+CapitalizeWords modifies the specified string so that the first letter of each
+whitespace separated word is uppercase and all other letters are lowercase.
+The whitespace between the words is preserved.
+*/
+func CapitalizeWords(
+	input string,
+) string {
+	var result sts.Builder
+	var startOfWord = true
+	for _, r := range input {
+		switch {
+		case uni.IsSpace(r):
+			startOfWord = true
+			result.WriteRune(r)
+		case startOfWord:
+			startOfWord = false
+			result.WriteRune(uni.ToUpper(r))
+		default:
+			result.WriteRune(uni.ToLower(r))
+		}
+	}
+	return result.String()
+}
+
+/*
+ContainsFold determines whether or not the specified needle string appears
+anywhere in the specified haystack string when case is ignored.  The strings
+are compared rune by rune using Unicode case folding rather than by converting
+both strings to lower case, which is lossy for some scripts.  An empty needle
+is contained in every haystack.
+*/
+func ContainsFold(
+	haystack string,
+	needle string,
+) bool {
+	if len(needle) == 0 {
+		return true
+	}
+	for start := range haystack {
+		var remaining = haystack[start:]
+		var pattern = needle
+		for len(remaining) > 0 && len(pattern) > 0 {
+			var first, firstSize = utf.DecodeRuneInString(remaining)
+			var second, secondSize = utf.DecodeRuneInString(pattern)
+			if !sts.EqualFold(string(first), string(second)) {
+				break
+			}
+			remaining = remaining[firstSize:]
+			pattern = pattern[secondSize:]
+		}
+		if len(pattern) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ConvertCase converts the specified identifier string from one case style into
+another case style.  The following case styles are supported:
+  - "lower"   -> lowerCaseValue    {a synonym for "camel"}
+  - "upper"   -> UpperCaseValue
+  - "snake"   -> snake-case-value
+  - "allcaps" -> ALL_CAPS_VALUE
+  - "camel"   -> camelCaseValue
+  - "title"   -> Title Case Value
+  - "dot"     -> dot.case.value
+
+If the "from" style is an empty string the style of the input string is detected
+automatically.  An unknown case style results in a panic.
+*/
+func ConvertCase(
+	input string,
+	fromStyle string,
+	toStyle string,
+) string {
+	if len(fromStyle) == 0 {
+		fromStyle = detectCase(input)
+	}
+	var words = splitWords(input, fromStyle)
+	return joinWords(words, toStyle)
+}
+
+/*
+Dedent removes the longest common leading whitespace from each line in the
+specified multi-line text.  Lines containing only whitespace are ignored when
+determining the common leading whitespace and are emptied in the result.  This
+allows indented raw string literals to be used as templates:
+
+	var text = Dedent(`
+		first line
+			indented line
+	`)
+*/
+func Dedent(
+	text string,
+) string {
+	var lines = sts.Split(text, "\n")
+	var prefix string
+	var found bool
+	for _, line := range lines {
+		if len(sts.TrimSpace(line)) == 0 {
+			continue
+		}
+		var indentation = line[:len(line)-len(sts.TrimLeft(line, " \t"))]
+		if !found {
+			prefix = indentation
+			found = true
+			continue
+		}
+		var size = 0
+		for size < len(prefix) && size < len(indentation) &&
+			prefix[size] == indentation[size] {
+			size++
+		}
+		prefix = prefix[:size]
+	}
+	for index, line := range lines {
+		if len(sts.TrimSpace(line)) == 0 {
+			lines[index] = ""
+		} else {
+			lines[index] = sts.TrimPrefix(line, prefix)
+		}
+	}
+	return sts.Join(lines, "\n")
+}
+
+/*
+Indent adds the specified prefix to the beginning of each line in the specified
+multi-line text.  Lines containing only whitespace are left unchanged.  It is
+the counterpart to the Dedent function.
+*/
+func Indent(
+	text string,
+	prefix string,
+) string {
+	var lines = sts.Split(text, "\n")
+	for index, line := range lines {
+		if len(sts.TrimSpace(line)) > 0 {
+			lines[index] = prefix + line
+		}
+	}
+	return sts.Join(lines, "\n")
+}
+
+/*
+Interpolate replaces each "${name}" placeholder in the specified template string
+with the corresponding value from the specified map as converted by the AsString
+function.  Any placeholders whose names are not in the map are left intact.
+*/
+func Interpolate(
+	template string,
+	values map[string]any,
+) string {
+	var builder sts.Builder
+	for {
+		var start = sts.Index(template, "${")
+		if start < 0 {
+			break
+		}
+		var end = sts.Index(template[start:], "}")
+		if end < 0 {
+			break
+		}
+		end += start
+		var name = template[start+2 : end]
+		var value, exists = values[name]
+		builder.WriteString(template[:start])
+		if exists {
+			builder.WriteString(AsString(value))
+		} else {
+			builder.WriteString(template[start : end+1])
+		}
+		template = template[end+1:]
+	}
+	builder.WriteString(template)
+	return builder.String()
+}
+
+/*
+IsBlank determines whether or not the specified string is empty or contains
+only Unicode whitespace characters.
+*/
+func IsBlank(
+	input string,
+) bool {
+	return len(sts.TrimSpace(input)) == 0
+}
+
+/*
+IsInteger determines whether or not the specified input string is a base 10
+integer (e.g. "42" or "-7") that fits in 64 bits.  An empty string is not an
+integer.
+*/
+func IsInteger(
+	input string,
+) bool {
+	var _, err = stc.ParseInt(input, 10, 64)
+	return err == nil
+}
+
+/*
+IsNotBlank determines whether or not the specified string contains at least one
+character that is not a Unicode whitespace character.
+*/
+func IsNotBlank(
+	input string,
+) bool {
+	return !IsBlank(input)
+}
+
+/*
+IsNumeric determines whether or not the specified input string is a finite
+floating point number (e.g. "42", "-3.14" or "6.02e23").  Empty strings and the
+special values "NaN" and "Inf" are not numeric.
+*/
+func IsNumeric(
+	input string,
+) bool {
+	var number, err = stc.ParseFloat(input, 64)
+	return err == nil && !mat.IsNaN(number) && !mat.IsInf(number, 0)
+}
+
+/*
+LongestCommonPrefix returns the longest string that is a prefix of each of the
+specified strings.  The strings are compared rune by rune.  It returns an empty
+string if the array of strings is empty or they share no common prefix.
+*/
+func LongestCommonPrefix(
+	strings []string,
+) string {
+	if len(strings) == 0 {
+		return ""
+	}
+	var prefix = []rune(strings[0])
+	for _, value := range strings[1:] {
+		var runes = []rune(value)
+		var size = 0
+		for size < len(prefix) && size < len(runes) && prefix[size] == runes[size] {
+			size++
+		}
+		prefix = prefix[:size]
+	}
+	return string(prefix)
+}
+
+/*
+LongestCommonSuffix returns the longest string that is a suffix of each of the
+specified strings.  The strings are compared rune by rune.  It returns an empty
+string if the array of strings is empty or they share no common suffix.
+*/
+func LongestCommonSuffix(
+	strings []string,
+) string {
+	if len(strings) == 0 {
+		return ""
+	}
+	var suffix = []rune(strings[0])
+	for _, value := range strings[1:] {
+		var runes = []rune(value)
+		var size = 0
+		for size < len(suffix) && size < len(runes) &&
+			suffix[len(suffix)-1-size] == runes[len(runes)-1-size] {
+			size++
+		}
+		suffix = suffix[len(suffix)-size:]
+	}
+	return string(suffix)
+}
+
+/*
+MakeAllCaps modifies the specified mixed case string into a corresponding all
+uppercase string using "_"s to separate the words found in the mixed case
+string.
+*/
+func MakeAllCaps(
+	mixedCase string,
+) string {
+	var allCaps sts.Builder
+	var foundLower bool
+	for _, r := range mixedCase {
+		switch {
+		case uni.IsLower(r):
+			foundLower = true
+			allCaps.WriteRune(uni.ToUpper(r))
+		case uni.IsUpper(r):
+			if foundLower {
+				allCaps.WriteString("_")
+				foundLower = false
+			}
+			allCaps.WriteRune(r)
+		default:
+			allCaps.WriteRune(r)
+		}
+	}
+	return allCaps.String()
 }
 
 /*
@@ -277,140 +1817,765 @@ func MakePlural(
 	default:
 		plural = mixedCase + "s"
 	}
-	return plural
+	return plural
+}
+
+/*
+MakeSentenceCase modifies the specified string so that its first letter is
+uppercase and all other letters are lowercase.
+*/
+func MakeSentenceCase(
+	input string,
+) string {
+	var runes = []rune(sts.ToLower(input))
+	for index, r := range runes {
+		if uni.IsLetter(r) {
+			runes[index] = uni.ToUpper(r)
+			break
+		}
+	}
+	return string(runes)
+}
+
+/*
+MakeSnakeCase modifies the specified mixed case string into a corresponding all
+lowercase string using "-"s to separate the words found in the mixed case
+string.
+*/
+func MakeSnakeCase(
+	mixedCase string,
+) string {
+	mixedCase = MakeLowerCase(mixedCase)
+	var snakeCase sts.Builder
+	for _, r := range mixedCase {
+		switch {
+		case uni.IsLower(r):
+			snakeCase.WriteRune(r)
+		case uni.IsUpper(r):
+			snakeCase.WriteString("-")
+			snakeCase.WriteRune(uni.ToLower(r))
+		default:
+			snakeCase.WriteRune(r)
+		}
+	}
+	return snakeCase.String()
+}
+
+/*
+MakeUpperCase modifies the specified mixed case string into a corresponding
+string starting with an uppercase letter.  All other letters remain unchanged.
+*/
+func MakeUpperCase(
+	mixedCase string,
+) string {
+	var upperCase string
+	if len(mixedCase) > 0 {
+		runes := []rune(mixedCase)
+		runes[0] = uni.ToUpper(runes[0])
+		upperCase = string(runes)
+	}
+	return upperCase
+}
+
+/*
+NormalizeString returns the Unicode normalization form C (NFC) of the specified
+string.  Characters that are represented by combining sequences (e.g. "e" plus
+a combining acute accent) are replaced by their precomposed forms (e.g. "é") so
+that case transformations and comparisons behave predictably.
+*/
+func NormalizeString(
+	input string,
+) string {
+	return nor.NFC.String(input)
+}
+
+/*
+ReplaceAll replaces each instance of the specified name embedded in angle
+brackets (i.e. "<" and ">") with the specified value throughout the specified
+template string.  The way the name is shown in the brackets determines what
+transformations are done on the value prior to the substitution as follows:
+  - <anyCaseName>      -> value              {leave value as is}
+  - <lowerCaseName_>   -> lowerCaseValue[_]  {convert value to unique ⃰lower case}
+  - <~lowerCaseName>   -> lowerCaseValue     {convert value to lower case}
+  - <~snake-case-name> -> snake-case-value   {convert value to snake case}
+  - <~UpperCaseName>   -> UpperCaseValue     {convert value to upper case}
+  - <~ALL_CAPS_NAME>   -> ALL_CAPS_VALUE     {convert value to all caps with _'s}
+
+⃰A trailing underscore "_" is added if the value collides with a Go keyword.
+*/
+func ReplaceAll(
+	template string,
+	name string,
+	value string,
+) string {
+	// <anyCaseName> -> value
+	var anyCaseName = MakeLowerCase(name)
+	template = sts.ReplaceAll(template, "<"+anyCaseName+">", value)
+	anyCaseName = MakeUpperCase(name)
+	template = sts.ReplaceAll(template, "<"+anyCaseName+">", value)
+
+	// <lowerCaseName_> -> lowerCaseValue[_]
+	var lowerCaseName = MakeLowerCase(name) + "_"
+	var lowerCaseValue = MakeLowerCase(value)
+	switch lowerCaseValue {
+	// Check to see if the value is a Go reserved word.
+	case "any", "append", "bool", "break", "byte", "cap", "case",
+		"chan", "clear", "close", "comparable", "complex", "const",
+		"continue", "copy", "default", "defer", "delete", "else",
+		"error", "fallthrough", "false", "for", "func", "go", "goto",
+		"if", "imag", "import", "int", "interface", "iota", "len",
+		"make", "map", "max", "min", "new", "nil", "package", "panic",
+		"print", "println", "range", "real", "recover", "return",
+		"rune", "select", "string", "struct", "switch", "true", "type",
+		"uint", "uintptr", "var":
+		lowerCaseValue += "_"
+	}
+	template = sts.ReplaceAll(template, "<"+lowerCaseName+">", lowerCaseValue)
+
+	// <~lowerCaseName> -> lowerCaseValue
+	lowerCaseName = "~" + MakeLowerCase(name)
+	lowerCaseValue = MakeLowerCase(value)
+	template = sts.ReplaceAll(template, "<"+lowerCaseName+">", lowerCaseValue)
+
+	// <~snake-case-name> -> snake-case-value
+	var snakeCaseName = "~" + MakeSnakeCase(name)
+	var snakeCaseValue = MakeSnakeCase(value)
+	template = sts.ReplaceAll(template, "<"+snakeCaseName+">", snakeCaseValue)
+
+	// <~UpperCaseName> -> UpperCaseValue
+	var upperCaseName = "~" + MakeUpperCase(name)
+	var upperCaseValue = MakeUpperCase(value)
+	template = sts.ReplaceAll(template, "<"+upperCaseName+">", upperCaseValue)
+
+	// <~ALL_CAPS_NAME> -> ALL_CAPS_VALUE
+	var allCapsName = "~" + MakeAllCaps(name)
+	var allCapsValue = MakeAllCaps(value)
+	template = sts.ReplaceAll(template, "<"+allCapsName+">", allCapsValue)
+
+	return template
+}
+
+/*
+ReplaceAllFromMapRecursive applies the ReplaceAll function to the specified
+template string for each name-value pair in the specified map of substitutions.
+The substitutions are repeated until the template no longer changes so that any
+placeholders contained in the substituted values are also replaced.  It panics
+if the substitutions do not converge (e.g. a value refers to its own name).
+*/
+func ReplaceAllFromMapRecursive(
+	template string,
+	substitutions map[string]string,
+) string {
+	var names = sortedKeys(substitutions)
+	for iteration := 0; iteration < maximumSubstitutions; iteration++ {
+		var previous = template
+		for _, name := range names {
+			template = ReplaceAll(template, name, substitutions[name])
+		}
+		if template == previous {
+			return template
+		}
+	}
+	var message = fmt.Sprintf(
+		"Attempted to replace recursive placeholders more than %v times.",
+		maximumSubstitutions,
+	)
+	panic(message)
+}
+
+/*
+ReverseString returns a string containing the runes of the specified string in
+reverse order.  Multibyte characters are kept intact since the string is
+reversed rune by rune rather than byte by byte.
+*/
+func ReverseString(
+	input string,
+) string {
+	var runes = []rune(input)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+/*
+RuneCount returns the number of runes (Unicode code points) in the specified
+string.  This differs from the Go len() function which returns the number of
+bytes in the string.  Any multibyte character counts as a single rune but as
+multiple bytes.
+*/
+func RuneCount(
+	input string,
+) uint {
+	return uint(utf.RuneCountInString(input))
+}
+
+/*
+TrimPrefixKeepCase removes the specified prefix from the specified identifier
+only when the remaining identifier starts at a word boundary (i.e. with an upper
+case letter).  The first letter of the remaining identifier is then given the
+case of the first letter of the original identifier.  For example, both
+"getFooBar" and "GetFooBar" with the prefix "get" or "Get" respectively become
+"fooBar" and "FooBar".  Otherwise the identifier is returned unchanged.
+*/
+func TrimPrefixKeepCase(
+	identifier string,
+	prefix string,
+) string {
+	var remainder, found = sts.CutPrefix(identifier, prefix)
+	if !found || len(prefix) == 0 {
+		return identifier
+	}
+	var next, size = utf.DecodeRuneInString(remainder)
+	if !uni.IsUpper(next) {
+		return identifier
+	}
+	var first, _ = utf.DecodeRuneInString(identifier)
+	if uni.IsLower(first) {
+		next = uni.ToLower(next)
+	}
+	return string(next) + remainder[size:]
+}
+
+/*
+TrimSuffixKeepCase removes the specified suffix from the specified identifier
+only when the suffix starts at a word boundary (i.e. with an upper case letter)
+and the remaining identifier is not empty.  For example, "fooBar" with the
+suffix "Bar" becomes "foo".  Otherwise the identifier is returned unchanged.
+*/
+func TrimSuffixKeepCase(
+	identifier string,
+	suffix string,
+) string {
+	var remainder, found = sts.CutSuffix(identifier, suffix)
+	if !found || len(remainder) == 0 {
+		return identifier
+	}
+	var first, _ = utf.DecodeRuneInString(suffix)
+	if !uni.IsUpper(first) {
+		return identifier
+	}
+	return remainder
+}
+
+/*
+Format returns a canonical string describing any value in Go.  It takes into
+account the nesting depth of all compound values (i.e. arrays, maps and structs)
+and indents each four spaces per nesting level.  This function does not call the
+Go "Stringer" interface on any of the values even if the value supports it since
+this the "Stringer" interface does not take into account the nesting depth.
+
+That said, the Go "Stringer" interface can be safely implemented using the
+Format function as follows:
+
+	 func (v *MyClass) String() string {
+		 return uti.Format(v)
+	 }
+
+There should be no risk of infinite recursion from Format() calling String()
+calling Format() calling String()...
+*/
+func Format(
+	value any,
+) string {
+	return FormatWithOptions(value, FormatOptions{})
+}
+
+/*
+FormatBounded returns a canonical string describing any value in Go that is no
+longer than the specified maximum number of bytes.  If the formatted string
+would be longer, formatting stops and the string is truncated and marked with a
+trailing "…(truncated)" so that the result, including the marker, stays within
+the bound.  If the bound is smaller than the marker only as much of the marker
+as fits is returned.  See the Format function for the details.
+*/
+func FormatBounded(
+	value any,
+	maxBytes uint,
+) (result string) {
+	var writer = &boundedWriter_{
+		limit_: int(maxBytes),
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			if e != errTruncated {
+				panic(e)
+			}
+			// Shorten the marker itself if the bound is too small to hold it.
+			var marker = truncationMarker
+			if int(maxBytes) < len(marker) {
+				var size = int(maxBytes)
+				for size > 0 && !utf.RuneStart(marker[size]) {
+					size--
+				}
+				result = marker[:size]
+				return
+			}
+			// Make room for the marker without splitting a multibyte rune.
+			var text = writer.builder_.String()
+			var size = int(maxBytes) - len(marker)
+			for size > 0 && !utf.RuneStart(text[size]) {
+				size--
+			}
+			result = text[:size] + marker
+		}
+	}()
+	formatTo(writer, value, FormatOptions{})
+	return writer.builder_.String()
+}
+
+/*
+FormatTabbed returns a canonical string describing any value in Go that is
+indented using a single tab character per nesting level rather than four spaces.
+See the Format function for the details.
+*/
+func FormatTabbed(
+	value any,
+) string {
+	var options = FormatOptions{
+		Indentation: "\t",
+	}
+	return FormatWithOptions(value, options)
+}
+
+/*
+FormatTo writes the canonical string describing any value in Go to the specified
+writer.  The string is streamed to the writer as it is formatted rather than
+being built in memory first.  See the Format function for the details.  It
+panics if the writer returns an error.
+*/
+func FormatTo(
+	writer iox.Writer,
+	value any,
+) {
+	formatTo(writer, value, FormatOptions{})
+}
+
+/*
+FormatWithOptions returns a canonical string describing any value in Go using
+the specified formatting options.  See the Format function for the details.
+*/
+func FormatWithOptions(
+	value any,
+	options FormatOptions,
+) string {
+	var builder sts.Builder
+	formatTo(&builder, value, options)
+	return builder.String()
+}
+
+/*
+FormatWithRenderer returns a canonical string describing any value in Go.  Each
+value, including nested values, is first passed to the specified render function.
+If the function returns true, its string is used for the value.  Otherwise the
+value is formatted normally.  See the Format function for the details.
+*/
+func FormatWithRenderer(
+	value any,
+	render func(value any) (string, bool),
+) string {
+	var options = FormatOptions{
+		Renderer: render,
+	}
+	return FormatWithOptions(value, options)
 }
 
 /*
-MakeSnakeCase modifies the specified mixed case string into a corresponding all
-lowercase string using "-"s to separate the words found in the mixed case
-string.
+FormatWithTiming returns a canonical string describing any value in Go along
+with the amount of time it took to format the value.  It is useful when
+profiling the cost of formatting large values.  See the Format function for the
+details.
 */
-func MakeSnakeCase(
-	mixedCase string,
-) string {
-	mixedCase = MakeLowerCase(mixedCase)
-	var snakeCase sts.Builder
-	for _, r := range mixedCase {
-		switch {
-		case uni.IsLower(r):
-			snakeCase.WriteRune(r)
-		case uni.IsUpper(r):
-			snakeCase.WriteString("-")
-			snakeCase.WriteRune(uni.ToLower(r))
-		default:
-			snakeCase.WriteRune(r)
-		}
+func FormatWithTiming(
+	value any,
+) (string, tim.Duration) {
+	var start = tim.Now()
+	var result = Format(value)
+	var duration = tim.Since(start)
+	return result, duration
+}
+
+// Numbers
+
+/*
+DivMod returns the quotient and remainder of the specified dividend divided by
+the specified divisor.  Rather than panicking, it returns false if the divisor
+is zero.  It follows the Go semantics for integer division which truncates the
+quotient toward zero, so the remainder has the same sign as the dividend (e.g.
+-7 divided by 2 results in a quotient of -3 and a remainder of -1).
+*/
+func DivMod(
+	dividend int,
+	divisor int,
+) (
+	quotient int,
+	remainder int,
+	ok bool,
+) {
+	if divisor == 0 {
+		return
 	}
-	return snakeCase.String()
+	quotient = dividend / divisor
+	remainder = dividend % divisor
+	ok = true
+	return
 }
 
 /*
-MakeUpperCase modifies the specified mixed case string into a corresponding
-string starting with an uppercase letter.  All other letters remain unchanged.
+ParseDurationOrDefault returns the duration described by the specified text
+(e.g. "1h30m" or "250ms") using the Go duration syntax.  It returns the
+specified fallback duration if the text is empty or cannot be parsed.
 */
-func MakeUpperCase(
-	mixedCase string,
+func ParseDurationOrDefault(
+	text string,
+	fallback tim.Duration,
+) tim.Duration {
+	var duration, err = tim.ParseDuration(text)
+	if err != nil {
+		duration = fallback
+	}
+	return duration
+}
+
+// Random Values
+
+/*
+RandomHex returns a string containing the specified number of cryptographically
+secure random bytes encoded as lowercase hexadecimal digits.  The resulting
+string contains two characters per byte.
+*/
+func RandomHex(
+	size uint,
 ) string {
-	var upperCase string
-	if len(mixedCase) > 0 {
-		runes := []rune(mixedCase)
-		runes[0] = uni.ToUpper(runes[0])
-		upperCase = string(runes)
+	var bytes = make([]byte, size)
+	var _, err = ran.Read(bytes)
+	if err != nil {
+		panic(err)
 	}
-	return upperCase
+	return hex.EncodeToString(bytes)
 }
 
+// Functions
+
 /*
-ReplaceAll replaces each instance of the specified name embedded in angle
-brackets (i.e. "<" and ">") with the specified value throughout the specified
-template string.  The way the name is shown in the brackets determines what
-transformations are done on the value prior to the substitution as follows:
-  - <anyCaseName>      -> value              {leave value as is}
-  - <lowerCaseName_>   -> lowerCaseValue[_]  {convert value to unique ⃰lower case}
-  - <~lowerCaseName>   -> lowerCaseValue     {convert value to lower case}
-  - <~snake-case-name> -> snake-case-value   {convert value to snake case}
-  - <~UpperCaseName>   -> UpperCaseValue     {convert value to upper case}
-  - <~ALL_CAPS_NAME>   -> ALL_CAPS_VALUE     {convert value to all caps with _'s}
+Lazy[V any] returns a function that calls the specified initializer the first
+time it is called and returns the cached result on every call.  The initializer
+is called exactly once even when the returned function is called concurrently.
+If the initializer panics, every call panics with the same value.
+*/
+func Lazy[V any](
+	initializer func() V,
+) func() V {
+	return syn.OnceValue(initializer)
+}
 
-⃰A trailing underscore "_" is added if the value collides with a Go keyword.
+/*
+Memoize[K comparable, V any] returns a function that wraps the specified pure
+function and caches its results.  The specified function is called at most once
+for each distinct argument.  The returned function is safe for concurrent use and
+the specified function may call it recursively with different arguments.  If the
+specified function panics, every later call with the same argument panics with
+the same value.
 */
-func ReplaceAll(
-	template string,
-	name string,
-	value string,
-) string {
-	// <anyCaseName> -> value
-	var anyCaseName = MakeLowerCase(name)
-	template = sts.ReplaceAll(template, "<"+anyCaseName+">", value)
-	anyCaseName = MakeUpperCase(name)
-	template = sts.ReplaceAll(template, "<"+anyCaseName+">", value)
+func Memoize[K comparable, V any](
+	function func(K) V,
+) func(K) V {
+	var mutex syn.Mutex
+	var cache = make(map[K]func() V)
+	return func(argument K) V {
+		mutex.Lock()
+		var entry = cache[argument]
+		if entry == nil {
+			entry = syn.OnceValue(func() V {
+				return function(argument)
+			})
+			cache[argument] = entry
+		}
+		mutex.Unlock()
+		// The lock is not held while calling the function so that recursive
+		// and concurrent calls with other arguments can proceed.
+		return entry()
+	}
+}
 
-	// <lowerCaseName_> -> lowerCaseValue[_]
-	var lowerCaseName = MakeLowerCase(name) + "_"
-	var lowerCaseValue = MakeLowerCase(value)
-	switch lowerCaseValue {
-	// Check to see if the value is a Go reserved word.
-	case "any", "append", "bool", "break", "byte", "cap", "case",
-		"chan", "clear", "close", "comparable", "complex", "const",
-		"continue", "copy", "default", "defer", "delete", "else",
-		"error", "fallthrough", "false", "for", "func", "go", "goto",
-		"if", "imag", "import", "int", "interface", "iota", "len",
-		"make", "map", "max", "min", "new", "nil", "package", "panic",
-		"print", "println", "range", "real", "recover", "return",
-		"rune", "select", "string", "struct", "switch", "true", "type",
-		"uint", "uintptr", "var":
-		lowerCaseValue += "_"
+/*
+MemoizeAny[K any, V any] returns a function that wraps the specified pure
+function and caches its results.  Unlike the Memoize function the argument type
+need not be comparable.  Arguments are considered to be the same if they have
+the same dynamic type and the Format function returns the same string for each
+of them.  This means that pointers to different values with the same contents
+share a cached result.  Since such strings must identify the arguments exactly,
+it panics if an argument contains anything that the Format function omits (e.g.
+private fields, values nested too deeply, channels, functions or class
+instances).
+*/
+func MemoizeAny[K any, V any](
+	function func(K) V,
+) func(K) V {
+	var mutex syn.Mutex
+	var cache = make(map[string]func() V)
+	return func(argument K) V {
+		var key, exact = formatExactly(argument)
+		if !exact {
+			var message = fmt.Sprintf(
+				"Attempted to memoize an argument that cannot be formatted exactly: %v",
+				key,
+			)
+			panic(message)
+		}
+		// The formatted string does not include the type of scalar values, so
+		// it is qualified by the dynamic type of the argument (e.g. when K is
+		// any).
+		var reflected = ref.ValueOf(argument)
+		if reflected.IsValid() {
+			key = "(" + formatType(reflected.Type()) + ")" + key
+		}
+		mutex.Lock()
+		var entry = cache[key]
+		if entry == nil {
+			entry = syn.OnceValue(func() V {
+				return function(argument)
+			})
+			cache[key] = entry
+		}
+		mutex.Unlock()
+		return entry()
 	}
-	template = sts.ReplaceAll(template, "<"+lowerCaseName+">", lowerCaseValue)
+}
 
-	// <~lowerCaseName> -> lowerCaseValue
-	lowerCaseName = "~" + MakeLowerCase(name)
-	lowerCaseValue = MakeLowerCase(value)
-	template = sts.ReplaceAll(template, "<"+lowerCaseName+">", lowerCaseValue)
+/*
+Tap[V any] calls the specified inspect function on the specified value for its
+side effects (e.g. logging) and returns the value unchanged.  This allows values
+to be inspected in the middle of an expression.
+*/
+func Tap[V any](
+	value V,
+	inspect func(V),
+) V {
+	inspect(value)
+	return value
+}
 
-	// <~snake-case-name> -> snake-case-value
-	var snakeCaseName = "~" + MakeSnakeCase(name)
-	var snakeCaseValue = MakeSnakeCase(value)
-	template = sts.ReplaceAll(template, "<"+snakeCaseName+">", snakeCaseValue)
+// Errors
 
-	// <~UpperCaseName> -> UpperCaseValue
-	var upperCaseName = "~" + MakeUpperCase(name)
-	var upperCaseValue = MakeUpperCase(value)
-	template = sts.ReplaceAll(template, "<"+upperCaseName+">", upperCaseValue)
+/*
+Must[V any] returns the specified value if the specified error is nil, otherwise
+it panics with the error.  It converts a function call that returns an error
+into one that panics:
 
-	// <~ALL_CAPS_NAME> -> ALL_CAPS_VALUE
-	var allCapsName = "~" + MakeAllCaps(name)
-	var allCapsValue = MakeAllCaps(value)
-	template = sts.ReplaceAll(template, "<"+allCapsName+">", allCapsValue)
+	var source = Must(os.ReadFile(filename))
+*/
+func Must[V any](
+	value V,
+	err error,
+) V {
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
 
-	return template
+/*
+Recover calls the specified function and converts any panic that occurs during
+the call into an error.  It returns nil if the function does not panic.  It is
+the counterpart to the Must function.
+*/
+func Recover(
+	function func(),
+) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			switch actual := e.(type) {
+			case error:
+				err = actual
+			default:
+				err = fmt.Errorf("%v", actual)
+			}
+		}
+	}()
+	function()
+	return err
 }
 
 /*
-Format returns a canonical string describing any value in Go.  It takes into
-account the nesting depth of all compound values (i.e. arrays, maps and structs)
-and indents each four spaces per nesting level.  This function does not call the
-Go "Stringer" interface on any of the values even if the value supports it since
-this the "Stringer" interface does not take into account the nesting depth.
+Retry calls the specified operation until it completes without panicking or the
+specified number of attempts has been made.  It returns nil if an attempt
+succeeds, otherwise it returns the panic from the final attempt as an error.  It
+panics if the number of attempts is zero.
+*/
+func Retry(
+	attempts uint,
+	operation func(),
+) error {
+	if attempts == 0 {
+		var message = "Attempted to retry an operation with zero attempts."
+		panic(message)
+	}
+	var err error
+	for attempt := uint(0); attempt < attempts; attempt++ {
+		err = Recover(operation)
+		if err == nil {
+			break
+		}
+	}
+	return err
+}
 
-That said, the Go "Stringer" interface can be safely implemented using the
-Format function as follows:
+// Reflection
 
-	 func (v *MyClass) String() string {
-		 return uti.Format(v)
-	 }
+/*
+AsArray returns a new array containing each of the elements of the specified
+slice or array value.  It panics if the value is not a slice or array.
+*/
+func AsArray(
+	value any,
+) []any {
+	var reflected = ref.ValueOf(value)
+	switch reflected.Kind() {
+	case ref.Array, ref.Slice:
+		var size = reflected.Len()
+		var array = make([]any, size)
+		for index := 0; index < size; index++ {
+			array[index] = reflected.Index(index).Interface()
+		}
+		return array
+	default:
+		var message = fmt.Sprintf(
+			"Attempted to convert a non-array value into an array: %v of type %T",
+			value,
+			value,
+		)
+		panic(message)
+	}
+}
 
-There should be no risk of infinite recursion from Format() calling String()
-calling Format() calling String()...
+/*
+AsString returns a string describing the specified value that is suitable for
+logging.  String values are returned as is, primitive values are converted into
+their concise string forms, and all other values are converted using the Format
+function.  Following the conventions of the Format function, int32 values are
+treated as runes.
 */
-func Format(
+func AsString(
 	value any,
 ) string {
 	var reflected = ref.ValueOf(value)
-	return formatValue(reflected, 0)
+	switch reflected.Kind() {
+	case ref.String:
+		return reflected.String()
+	case ref.Bool:
+		return stc.FormatBool(reflected.Bool())
+	case ref.Uint, ref.Uint8, ref.Uint16, ref.Uint32, ref.Uint64, ref.Uintptr:
+		return stc.FormatUint(reflected.Uint(), 10)
+	case ref.Int, ref.Int8, ref.Int16, ref.Int64:
+		return stc.FormatInt(reflected.Int(), 10)
+	case ref.Int32:
+		return string(rune(reflected.Int()))
+	case ref.Float32, ref.Float64:
+		var bits = reflected.Type().Bits()
+		return stc.FormatFloat(reflected.Float(), 'G', -1, bits)
+	case ref.Complex64, ref.Complex128:
+		var bits = reflected.Type().Bits()
+		return stc.FormatComplex(reflected.Complex(), 'G', -1, bits)
+	default:
+		return Format(value)
+	}
 }
 
-// Reflection
+/*
+Coalesce[V any] returns the first of the specified values that is defined as
+determined by the IsDefined function.  It returns the zero value of the type if
+none of the values are defined.
+*/
+func Coalesce[V any](
+	values ...V,
+) V {
+	for _, value := range values {
+		if IsDefined(value) {
+			return value
+		}
+	}
+	var zero V
+	return zero
+}
+
+/*
+Default[V any] returns the specified value if it is defined as determined by the
+IsDefined function, otherwise it returns the specified fallback value.  Empty
+strings, nil pointers, nil arrays and nil maps are considered to be undefined
+while zero numeric values are considered to be defined.
+*/
+func Default[V any](
+	value V,
+	fallback V,
+) V {
+	if IsUndefined(value) {
+		return fallback
+	}
+	return value
+}
+
+/*
+Equal determines whether or not the specified values are equal.  Arrays are
+equal if they have equal elements in the same order and maps are equal if they
+have the same keys associated with equal values, as determined recursively by
+this function.  All other values are equal if they have the same type and their
+canonical strings returned by the Format function are the same.  Values whose
+canonical strings omit something (e.g. private fields) are instead compared
+deeply.  Values having different types are never equal.
+*/
+func Equal(
+	first any,
+	second any,
+) bool {
+	var firstValue = ref.ValueOf(first)
+	var secondValue = ref.ValueOf(second)
+	if !firstValue.IsValid() || !secondValue.IsValid() {
+		return firstValue.IsValid() == secondValue.IsValid()
+	}
+	if firstValue.Type() != secondValue.Type() {
+		return false
+	}
+	switch firstValue.Kind() {
+	case ref.Array, ref.Slice:
+		var size = firstValue.Len()
+		if size != secondValue.Len() {
+			return false
+		}
+		for index := 0; index < size; index++ {
+			if !Equal(
+				firstValue.Index(index).Interface(),
+				secondValue.Index(index).Interface(),
+			) {
+				return false
+			}
+		}
+		return true
+	case ref.Map:
+		if firstValue.Len() != secondValue.Len() {
+			return false
+		}
+		var iterator = firstValue.MapRange()
+		for iterator.Next() {
+			var value = secondValue.MapIndex(iterator.Key())
+			if !value.IsValid() || !Equal(
+				iterator.Value().Interface(),
+				value.Interface(),
+			) {
+				return false
+			}
+		}
+		return true
+	default:
+		var firstString, firstExact = formatExactly(first)
+		var secondString, secondExact = formatExactly(second)
+		if !firstExact || !secondExact {
+			// NOTE: The formatted strings cannot distinguish the omitted parts.
+			return ref.DeepEqual(first, second)
+		}
+		return firstString == secondString
+	}
+}
 
 /*
 ImplementsInterface checks whether or not the specified value implements the
@@ -476,78 +2641,227 @@ func IsDefined(
 			isDefined = !isNil && meta.IsValid()
 		}
 	}
-	return isDefined
+	return isDefined
+}
+
+/*
+IsUndefined checks whether or not the specified value is undefined.  Empty
+strings and nil pointers are considered as being undefined.
+*/
+func IsUndefined(
+	value any,
+) bool {
+	return !IsDefined(value)
+}
+
+// Private
+
+const maximumDepth = 8
+
+const maximumSubstitutions = 32
+
+const (
+	defaultCompactSize  = 5
+	defaultCompactWidth = 60
+	defaultIndentation  = "    "
+)
+
+const truncationMarker = "…(truncated)"
+
+var errTruncated = fmt.Errorf("the formatted output was truncated")
+
+var stringerType = ref.TypeOf((*fmt.Stringer)(nil)).Elem()
+
+// The name of the method is looked up rather than hard coded so that renaming
+// it cannot silently break the check made by the isAnnotating function.
+var annotatingFunction = run.FuncForPC(
+	ref.ValueOf((*formatter_).annotateStringer).Pointer(),
+).Name()
+
+var syncTypes = map[ref.Type]bool{
+	ref.TypeOf((*syn.Map)(nil)).Elem():       true,
+	ref.TypeOf((*syn.Mutex)(nil)).Elem():     true,
+	ref.TypeOf((*syn.Once)(nil)).Elem():      true,
+	ref.TypeOf((*syn.RWMutex)(nil)).Elem():   true,
+	ref.TypeOf((*syn.WaitGroup)(nil)).Elem(): true,
+}
+
+type boundedWriter_ struct {
+	builder_ sts.Builder
+	limit_   int
+}
+
+func (v *boundedWriter_) Write(
+	bytes []byte,
+) (int, error) {
+	var available = v.limit_ - v.builder_.Len()
+	if len(bytes) > available {
+		v.builder_.Write(bytes[:available])
+		return available, errTruncated
+	}
+	return v.builder_.Write(bytes)
+}
+
+type formatter_ struct {
+	options_ FormatOptions
+	omitted_ bool
+	visited_ map[visit_]bool
+	writer_  iox.Writer
+}
+
+type visit_ struct {
+	type_    ref.Type
+	address_ uintptr
+}
+
+func (v *formatter_) annotateStringer(
+	reflected ref.Value,
+) {
+	// NOTE: Values with a pointer receiver String() method are copied into an
+	// addressable value so that the method can be called on them as well.
+	if reflected.Kind() == ref.Interface || !reflected.CanInterface() {
+		return
+	}
+	var valueType = reflected.Type()
+	if !valueType.Implements(stringerType) {
+		if !ref.PointerTo(valueType).Implements(stringerType) {
+			return
+		}
+		if containsSync(valueType) {
+			// Copying the value would copy a lock.
+			return
+		}
+		var addressable = ref.New(valueType)
+		addressable.Elem().Set(reflected)
+		reflected = addressable
+	}
+
+	// A String() method that panics (e.g. on a nil receiver) is not annotated.
+	var annotation string
+	var err = Recover(func() {
+		annotation = reflected.Interface().(fmt.Stringer).String()
+	})
+	if err == nil {
+		v.write(" // " + annotation)
+	}
+}
+
+func capitalizeWord(
+	word string,
+) string {
+	var runes = []rune(sts.ToLower(word))
+	if len(runes) > 0 {
+		runes[0] = uni.ToUpper(runes[0])
+	}
+	return string(runes)
 }
 
-/*
-IsUndefined checks whether or not the specified value is undefined.  Empty
-strings and nil pointers are considered as being undefined.
-*/
-func IsUndefined(
-	value any,
+func containsSync(
+	reflectedType ref.Type,
 ) bool {
-	return !IsDefined(value)
+	// NOTE: Only values stored directly (rather than through a pointer) in a
+	// structure or array are copied along with it.
+	if syncTypes[reflectedType] {
+		return true
+	}
+	switch reflectedType.Kind() {
+	case ref.Array:
+		return containsSync(reflectedType.Elem())
+	case ref.Struct:
+		for index := 0; index < reflectedType.NumField(); index++ {
+			if containsSync(reflectedType.Field(index).Type) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
-// Private
+func detectCase(
+	input string,
+) string {
+	switch {
+	case sts.Contains(input, "_"):
+		return "allcaps"
+	case sts.Contains(input, "-"):
+		return "snake"
+	case sts.Contains(input, "."):
+		return "dot"
+	case sts.Contains(input, " "):
+		return "title"
+	default:
+		return "camel"
+	}
+}
 
-const maximumDepth = 8
+func (v *formatter_) capture(
+	format func(),
+) string {
+	// Temporarily redirect the output into a string.  The original writer is
+	// restored even if the formatting panics (e.g. in a getter method).
+	var writer = v.writer_
+	defer func() {
+		v.writer_ = writer
+	}()
+	var buffer sts.Builder
+	v.writer_ = &buffer
+	format()
+	return buffer.String()
+}
 
-func formatArray(
+func (v *formatter_) formatArray(
 	reflected ref.Value,
 	depth uint,
-) string {
-	var result = "["
+) {
+	v.write("[")
 	var size = reflected.Len()
 	if size == 0 {
 		// This is an empty array.
-		result += " "
+		v.write(" ")
 	} else {
 		// This is a multivalued array.
 		if depth < maximumDepth {
 			depth++
-			for index := 0; index < size; index++ {
-				result += formatNewline(depth)
-				var value = reflected.Index(index)
-				result += formatValue(value, depth)
-			}
-			depth--
-			result += formatNewline(depth)
+			v.formatElements(
+				size,
+				func(index int) {
+					var value = reflected.Index(index)
+					v.formatValue(value, depth)
+				},
+				depth,
+			)
 		} else {
-			result += "..."
+			v.omit("...")
 		}
 	}
 	var typeName = formatType(reflected.Type())
-	result += "](" + typeName + ")"
-	return result
+	v.write("](" + typeName + ")")
 }
 
-func formatAssociation(
+func (v *formatter_) formatAssociation(
 	key ref.Value,
 	value ref.Value,
 	depth uint,
-) string {
-	var result = formatValue(key, depth)
-	result += ": "
-	result += formatValue(value, depth)
-	return result
+) {
+	v.formatValue(key, depth)
+	v.write(": ")
+	v.formatValue(value, depth)
 }
 
-func formatAssociations(
+func (v *formatter_) formatAssociations(
 	reflected ref.Value,
 	depth uint,
-) string {
-	var result string
+) {
 	var size = reflected.Len()
 	if size == 0 {
 		// This is an empty sequence of associations.
-		result += ":"
+		v.write(":")
 	} else {
 		// This is a multivalued sequence of associations.
 		if depth < maximumDepth {
 			depth++
 			for index := 0; index < size; index++ {
-				result += formatNewline(depth)
+				v.formatNewline(depth)
 				var association = reflected.Index(index)
 				var key = association.MethodByName("GetKey").Call(
 					[]ref.Value{},
@@ -555,18 +2869,17 @@ func formatAssociations(
 				var value = association.MethodByName("GetValue").Call(
 					[]ref.Value{},
 				)[0]
-				result += formatAssociation(key, value, depth)
+				v.formatAssociation(key, value, depth)
 			}
 			depth--
-			result += formatNewline(depth)
+			v.formatNewline(depth)
 		} else {
-			result += "..."
+			v.omit("...")
 		}
 	}
-	return result
 }
 
-func formatBoolean(
+func (v *formatter_) formatBoolean(
 	reflected ref.Value,
 	depth uint,
 ) string {
@@ -574,10 +2887,12 @@ func formatBoolean(
 	return stc.FormatBool(value)
 }
 
-func formatChannel(
+func (v *formatter_) formatChannel(
 	reflected ref.Value,
 	depth uint,
-) string {
+) {
+	// The identity of a channel is not part of its formatted string.
+	v.omitted_ = true
 	var direction string
 	var reflectedType = reflected.Type()
 	switch reflectedType.ChanDir() {
@@ -588,46 +2903,117 @@ func formatChannel(
 	case ref.BothDir:
 		direction = "Both"
 	}
-	var result = "["
+	v.write("[")
 	depth++
-	result += formatNewline(depth)
-	result += "Direction: " + direction
-	result += formatNewline(depth)
-	result += "Capacity: " + stc.Itoa(reflected.Cap())
-	result += formatNewline(depth)
-	result += "Size: " + stc.Itoa(reflected.Len())
+	v.formatNewline(depth)
+	v.write("Direction: " + direction)
+	v.formatNewline(depth)
+	v.write("Capacity: " + stc.Itoa(reflected.Cap()))
+	v.formatNewline(depth)
+	v.write("Size: " + stc.Itoa(reflected.Len()))
 	depth--
-	result += formatNewline(depth)
+	v.formatNewline(depth)
 	var typeName = formatType(reflected.Type())
-	result += "](" + typeName + ")"
-	return result
+	v.write("](" + typeName + ")")
 }
 
-func formatComplex(
+func (v *formatter_) formatComplex(
 	reflected ref.Value,
 	depth uint,
 ) string {
 	var value = reflected.Complex()
+	if v.options_.PolarComplex {
+		var amplitude, phase = cmp.Polar(value)
+		var result = "("
+		result += stc.FormatFloat(amplitude, 'G', -1, 64)
+		result += "∠"
+		result += stc.FormatFloat(phase, 'G', -1, 64)
+		result += ")"
+		return result
+	}
 	return stc.FormatComplex(complex128(value), 'G', -1, 64)
 }
 
-func formatFloat(
+func (v *formatter_) formatElements(
+	size int,
+	formatElement func(index int),
+	depth uint,
+) {
+	// NOTE:
+	// Each element is formatted at the specified depth on its own line unless
+	// compact arrays are enabled.  In that case the elements must be captured
+	// first to determine whether or not they fit together on a single line.
+	if v.options_.CompactArrays {
+		var elements = make([]string, size)
+		for index := range elements {
+			elements[index] = v.capture(func() { formatElement(index) })
+		}
+		if v.isCompact(elements) {
+			v.write(sts.Join(elements, ", "))
+			return
+		}
+		for _, element := range elements {
+			v.formatNewline(depth)
+			v.write(element)
+		}
+	} else {
+		for index := 0; index < size; index++ {
+			v.formatNewline(depth)
+			formatElement(index)
+		}
+	}
+	depth--
+	v.formatNewline(depth)
+}
+
+func (v *formatter_) formatField(
+	reflectedType ref.Type,
+	field ref.StructField,
+) string {
+	var result = field.Name
+	if v.options_.ShowEmbedding {
+		// Prefix the name with the names of any embedded types it came from.
+		var prefix string
+		for _, index := range field.Index[:len(field.Index)-1] {
+			var embedded = reflectedType.Field(index)
+			prefix += embedded.Name + "."
+			reflectedType = embedded.Type
+			if reflectedType.Kind() == ref.Pointer {
+				reflectedType = reflectedType.Elem()
+			}
+		}
+		result = prefix + result
+	}
+	return result
+}
+
+func (v *formatter_) formatFloat(
 	reflected ref.Value,
 	depth uint,
 ) string {
 	var value = reflected.Float()
-	var result = stc.FormatFloat(float64(value), 'G', -1, 64)
-	if !sts.Contains(result, ".") && !sts.Contains(result, "E") {
+	// Use the precision of the actual type so that float32 values are shown as
+	// written (e.g. "1.1" rather than "1.100000023841858").
+	var bits = reflected.Type().Bits()
+	var result = stc.FormatFloat(value, 'G', -1, bits)
+	switch {
+	case mat.IsNaN(value) || mat.IsInf(value, 0):
+		// Mark the special values with their type so they stand out.
+		var typeName = formatType(reflected.Type())
+		result += "(" + typeName + ")"
+	case value == mat.Trunc(value) && !sts.ContainsAny(result, ".E"):
+		// Mark integer values that are not in scientific notation as floats.
 		result += ".0"
 	}
 	return result
 }
 
-func formatFunction(
+func (v *formatter_) formatFunction(
 	reflected ref.Value,
 	depth uint,
 ) string {
 	// Format the signature type rather than the function definition.
+	v.omitted_ = true
 	var functionName = run.FuncForPC(reflected.Pointer()).Name()
 	var functionSignature = formatType(reflected.Type())
 	if IsDefined(functionName) {
@@ -639,47 +3025,87 @@ func formatFunction(
 	return functionSignature
 }
 
-func formatInstance(
+func (v *formatter_) formatGroups(
 	reflected ref.Value,
+	keys []ref.Value,
 	depth uint,
-) string {
-	var result string
+) {
+	// NOTE:
+	// The keys have already been sorted by kind so each group of keys having
+	// the same kind is contiguous.
+	var previous ref.Kind
+	for index, key := range keys {
+		var kind = key.Kind()
+		if kind == ref.Interface {
+			kind = key.Elem().Kind()
+		}
+		if index == 0 || kind != previous {
+			v.formatNewline(depth)
+			v.write("// " + kind.String())
+			previous = kind
+		}
+		v.formatNewline(depth)
+		var value = reflected.MapIndex(key)
+		v.formatAssociation(key, value, depth)
+	}
+	depth--
+	v.formatNewline(depth)
+}
+
+func (v *formatter_) formatInstance(
+	reflected ref.Value,
+	depth uint,
+) {
+	// Only the attributes exposed by getter methods are formatted.
+	v.omitted_ = true
 	if depth < maximumDepth {
-		depth++
+		var getters []string
 		var reflectedType = reflected.Type()
 		var count = reflectedType.NumMethod()
 		for index := 0; index < count; index++ {
 			var methodName = reflectedType.Method(index).Name
 			if sts.HasPrefix(methodName, "Get") {
-				var method = reflected.MethodByName(methodName)
-				var methodType = method.Type()
+				var methodType = reflected.MethodByName(methodName).Type()
 				if methodType.NumIn() == 0 && methodType.NumOut() == 1 {
-					result += formatNewline(depth)
-					var attributeName = sts.TrimPrefix(methodName, "Get")
-					var attributeValue = method.Call(
-						[]ref.Value{},
-					)[0]
-					result += attributeName
-					result += ": "
-					if methodName == "GetClass" {
-						// Just format the class type to avoid any recursion.
-						var classType = methodType.Out(0)
-						result += formatType(classType)
-					} else {
-						result += formatValue(attributeValue, depth)
-					}
+					getters = append(getters, methodName)
 				}
 			}
 		}
+		if v.options_.InlineGetters && len(getters) == 1 &&
+			getters[0] != "GetClass" {
+			// Inline the value of the only getter.
+			var attributeValue = reflected.MethodByName(getters[0]).Call(
+				[]ref.Value{},
+			)[0]
+			v.formatValue(attributeValue, depth)
+			return
+		}
+		depth++
+		for _, methodName := range getters {
+			var method = reflected.MethodByName(methodName)
+			v.formatNewline(depth)
+			var attributeName = sts.TrimPrefix(methodName, "Get")
+			var attributeValue = method.Call(
+				[]ref.Value{},
+			)[0]
+			v.write(attributeName)
+			v.write(": ")
+			if methodName == "GetClass" {
+				// Just format the class type to avoid any recursion.
+				var classType = method.Type().Out(0)
+				v.write(formatType(classType))
+			} else {
+				v.formatValue(attributeValue, depth)
+			}
+		}
 		depth--
-		result += formatNewline(depth)
+		v.formatNewline(depth)
 	} else {
-		result += "..."
+		v.omit("...")
 	}
-	return result
 }
 
-func formatInteger(
+func (v *formatter_) formatInteger(
 	reflected ref.Value,
 	depth uint,
 ) string {
@@ -687,17 +3113,29 @@ func formatInteger(
 	return stc.FormatInt(int64(value), 10)
 }
 
-func formatInterface(
+func (v *formatter_) formatInterface(
 	reflected ref.Value,
 	depth uint,
-) string {
+) {
 	// NOTE:
 	// Since a class that implements an iterface must implement all methods
 	// defined in that interface we can just format the value behind the
-	// interface.
+	// interface.  Any pointer behind the interface is checked against the
+	// pointers currently being formatted so that cycles are detected.
 	var value = reflected.Elem()
-	var result = formatValue(value, depth)
-	return result
+	v.formatValue(value, depth)
+}
+
+func formatKey(
+	key ref.Value,
+) string {
+	var builder sts.Builder
+	var formatter = &formatter_{
+		visited_: make(map[visit_]bool),
+		writer_:  &builder,
+	}
+	formatter.formatValue(key, 0)
+	return builder.String()
 }
 
 var typeMap = map[ref.Kind]uint8{
@@ -729,10 +3167,10 @@ var typeMap = map[ref.Kind]uint8{
 	ref.UnsafePointer: 25,
 }
 
-func formatMap(
+func (v *formatter_) formatMap(
 	reflected ref.Value,
 	depth uint,
-) string {
+) {
 	// NOTE:
 	// The intrinsic Go map data type is non-deterministic.  The ordering of the
 	// keys is determined by a hash function which means that two maps with the
@@ -757,124 +3195,111 @@ func formatMap(
 	//  * complex values by their amplitudes
 	//  * runes by their unicode numbers
 	//  * strings alphabetically by the unicode number of their characters
+	//  * all other comparable keys (e.g. structures, arrays and pointers)
+	//    alphabetically by their formatted strings
 	//
-	var result = "["
+	v.write("[")
 	var size = reflected.Len()
 	if size == 0 {
 		// This is an empty map.
-		result += ":"
+		v.write(":")
 	} else {
 		// This is a multivalued map.
 		if depth < maximumDepth {
 			depth++
 			// First sort the keys since Go maps are deterministic.
 			var keys = reflected.MapKeys()
-			sor.SliceStable(
-				keys,
-				func(i, j int) bool {
-					// Convert wrapper types into their element types.
-					var firstKey = keys[i]
-					var secondKey = keys[j]
-					if firstKey.Kind() == ref.Interface {
-						firstKey = firstKey.Elem()
-					}
-					if secondKey.Kind() == ref.Interface {
-						secondKey = secondKey.Elem()
-					}
-					// Sort by key type if the keys have different types.
-					if firstKey.Kind() != secondKey.Kind() {
-						var firstType = typeMap[firstKey.Kind()]
-						var secondType = typeMap[secondKey.Kind()]
-						return firstType < secondType
-					}
-					// Sort by key value if they have the same type.
-					switch firstKey.Kind() {
-					case ref.Bool:
-						return !(firstKey.Bool()) && secondKey.Bool()
-					case ref.Int, ref.Int8, ref.Int16, ref.Int32, ref.Int64:
-						return firstKey.Int() < secondKey.Int()
-					case ref.Uint, ref.Uint8, ref.Uint16, ref.Uint32, ref.Uint64:
-						return firstKey.Uint() < secondKey.Uint()
-					case ref.Float32, ref.Float64:
-						return firstKey.Float() < secondKey.Float()
-					case ref.Complex64, ref.Complex128:
-						var firstAmplitude = cmp.Abs(firstKey.Complex())
-						var secondAmplitude = cmp.Abs(secondKey.Complex())
-						return firstAmplitude < secondAmplitude
-					case ref.String:
-						return firstKey.String() < secondKey.String()
-					default:
-						var message = fmt.Sprintf(
-							"Attempted to compare an unknown key type: %v of type %T",
-							firstKey.Interface(),
-							firstKey.Interface(),
-						)
-						panic(message)
-					}
-				},
-			)
+			sortKeys(keys)
 			// Format the key-value pairs in order.
-			for _, key := range keys {
-				result += formatNewline(depth)
-				var value = reflected.MapIndex(key)
-				result += formatAssociation(key, value, depth)
+			if v.options_.GroupMapKeys && hasMixedKinds(keys) {
+				v.formatGroups(reflected, keys, depth)
+			} else {
+				v.formatElements(
+					size,
+					func(index int) {
+						var key = keys[index]
+						var value = reflected.MapIndex(key)
+						v.formatAssociation(key, value, depth)
+					},
+					depth,
+				)
 			}
-			depth--
-			result += formatNewline(depth)
 		} else {
-			result += "..."
+			v.omit("...")
 		}
 	}
 	var typeName = formatType(reflected.Type())
-	result += "](" + typeName + ")"
-	return result
+	v.write("](" + typeName + ")")
 }
 
-func formatNewline(
+func (v *formatter_) formatNewline(
 	depth uint,
-) string {
-	var result = "\n"
-	var indentation = "    "
+) {
+	v.write("\n")
+	var indentation = v.options_.Indentation
+	if len(indentation) == 0 {
+		indentation = defaultIndentation
+	}
 	var level uint
 	for level < depth {
-		result += indentation
+		v.write(indentation)
 		level++
 	}
-	return result
 }
 
-func formatPointer(
+func (v *formatter_) formatPointer(
 	reflected ref.Value,
 	depth uint,
-) string {
-	var result = "&["
+) {
+	// Detect any cycles back to a pointer that is already being formatted.  The
+	// type is part of the key since a pointer to a structure and a pointer to
+	// its first field (or to any zero size value) share the same address.
+	if !reflected.IsNil() {
+		var visit = visit_{
+			type_:    reflected.Type(),
+			address_: reflected.Pointer(),
+		}
+		if v.visited_[visit] {
+			v.omit("<cycle>")
+			return
+		}
+		v.visited_[visit] = true
+		defer delete(v.visited_, visit)
+	}
+	v.write("&[")
 	switch {
+	case syncTypes[reflected.Type().Elem()]:
+		// Format the placeholder for the synchronization type.
+		var value = reflected.Elem()
+		v.formatValue(value, depth)
 	case reflected.MethodByName("GetKeys").IsValid():
 		// Format the sequence of associations.
 		var associations = reflected.MethodByName("AsArray").Call(
 			[]ref.Value{},
 		)[0]
-		result += formatAssociations(associations, depth)
+		v.formatAssociations(associations, depth)
 	case reflected.MethodByName("AsArray").IsValid():
 		// Format the sequence of values.
 		var values = reflected.MethodByName("AsArray").Call(
 			[]ref.Value{},
 		)[0]
-		result += formatSequence(values, depth)
+		v.formatSequence(values, depth)
 	case reflected.NumMethod() > 0:
 		// Format the instance of a class.
-		result += formatInstance(reflected, depth)
+		v.formatInstance(reflected, depth)
 	default:
 		// Dereference the pointer.
 		var value = reflected.Elem()
-		result += formatValue(value, depth)
+		v.formatValue(value, depth)
 	}
 	var typeName = formatType(reflected.Type())
-	result += "](" + typeName + ")"
-	return result
+	v.write("](" + typeName + ")")
+	if v.options_.ShowAddresses && !reflected.IsNil() {
+		v.write(fmt.Sprintf("@%#x", reflected.Pointer()))
+	}
 }
 
-func formatRune(
+func (v *formatter_) formatRune(
 	reflected ref.Value,
 	depth uint,
 ) string {
@@ -882,34 +3307,32 @@ func formatRune(
 	return stc.QuoteRune(value)
 }
 
-func formatSequence(
+func (v *formatter_) formatSequence(
 	reflected ref.Value,
 	depth uint,
-) string {
-	var result string
+) {
 	var size = reflected.Len()
 	if size == 0 {
 		// This is an empty sequence.
-		result += " "
+		v.write(" ")
 	} else {
 		// This is a multivalued sequence.
 		if depth < maximumDepth {
 			depth++
 			for index := 0; index < size; index++ {
-				result += formatNewline(depth)
+				v.formatNewline(depth)
 				var value = reflected.Index(index)
-				result += formatValue(value, depth)
+				v.formatValue(value, depth)
 			}
 			depth--
-			result += formatNewline(depth)
+			v.formatNewline(depth)
 		} else {
-			result += "..."
+			v.omit("...")
 		}
 	}
-	return result
 }
 
-func formatString(
+func (v *formatter_) formatString(
 	reflected ref.Value,
 	depth uint,
 ) string {
@@ -917,34 +3340,60 @@ func formatString(
 	return stc.Quote(value)
 }
 
-func formatStructure(
+func (v *formatter_) formatStructure(
 	reflected ref.Value,
 	depth uint,
-) string {
-	var result = "["
+) {
+	if v.options_.DetectNullable {
+		var value, isNullable = nullableValue(reflected)
+		if isNullable {
+			if value.IsValid() {
+				v.formatValue(value, depth)
+			} else {
+				v.write("<null>")
+			}
+			return
+		}
+	}
+	v.write("[")
 	if depth < maximumDepth {
 		depth++
-		var fields = ref.VisibleFields(reflected.Type())
-		for index, field := range fields {
-			result += formatNewline(depth)
-			var name = field.Name
-			result += name
-			result += ": "
+		var reflectedType = reflected.Type()
+		var fields = ref.VisibleFields(reflectedType)
+		if v.options_.SortFields {
+			sor.SliceStable(
+				fields,
+				func(i, j int) bool {
+					var first = v.formatField(reflectedType, fields[i])
+					var second = v.formatField(reflectedType, fields[j])
+					return first < second
+				},
+			)
+		}
+		for _, field := range fields {
+			v.formatNewline(depth)
+			var name = v.formatField(reflectedType, field)
+			v.write(name)
+			v.write(": ")
 			if field.IsExported() {
-				var value = reflected.Field(index)
-				result += formatValue(value, depth)
+				// Promoted fields may be reached through a nil embedded pointer.
+				var value, err = reflected.FieldByIndexErr(field.Index)
+				if err != nil {
+					v.write("<nil>")
+				} else {
+					v.formatValue(value, depth)
+				}
 			} else {
-				result += "<private>"
+				v.omit("<private>")
 			}
 		}
 		depth--
-		result += formatNewline(depth)
+		v.formatNewline(depth)
 	} else {
-		result += "..."
+		v.omit("...")
 	}
 	var typeName = formatType(reflected.Type())
-	result += "](" + typeName + ")"
-	return result
+	v.write("](" + typeName + ")")
 }
 
 func formatType(
@@ -1029,73 +3478,127 @@ func formatType(
 	return result
 }
 
-func formatUnsafe(
+func (v *formatter_) formatUnsafe(
 	reflected ref.Value,
 	depth uint,
 ) string {
+	v.omitted_ = true
 	return "<unsafe>"
 }
 
-func formatUnsigned(
+func (v *formatter_) formatUnsigned(
 	reflected ref.Value,
 	depth uint,
 ) string {
 	var value = reflected.Uint()
+	if v.options_.PrintableBytes && reflected.Kind() == ref.Uint8 &&
+		value >= ' ' && value <= '~' {
+		return stc.QuoteRune(rune(value))
+	}
 	return "0x" + stc.FormatUint(uint64(value), 16)
 }
 
-func formatValue(
+func formatTo(
+	writer iox.Writer,
+	value any,
+	options FormatOptions,
+) {
+	if options.AnnotateStringer && isAnnotating() {
+		// NOTE: A String() method may itself format its value with this option
+		// so any formatting done during an annotation is not annotated.  This
+		// prevents infinite recursion.
+		options.AnnotateStringer = false
+	}
+	var formatter = &formatter_{
+		options_: options,
+		visited_: make(map[visit_]bool),
+		writer_:  writer,
+	}
+	var reflected = ref.ValueOf(value)
+	formatter.formatValue(reflected, 0)
+}
+
+func formatExactly(
+	value any,
+) (string, bool) {
+	// NOTE: The formatted string is exact only if no part of the value (e.g.
+	// private fields or values nested too deeply) was omitted from it.
+	var builder sts.Builder
+	var formatter = &formatter_{
+		visited_: make(map[visit_]bool),
+		writer_:  &builder,
+	}
+	formatter.formatValue(ref.ValueOf(value), 0)
+	return builder.String(), !formatter.omitted_
+}
+
+func (v *formatter_) formatValue(
 	reflected ref.Value,
 	depth uint,
-) string {
+) {
 	if !reflected.IsValid() {
-		return "<nil>"
+		v.write("<nil>")
+		return
+	}
+	if syncTypes[reflected.Type()] {
+		// NOTE: The internal state of a synchronization type is meaningless
+		// and reflecting into it risks copying a lock, so a placeholder is used.
+		// This is checked before calling the renderer which would copy it.
+		v.omit("<" + reflected.Type().String() + ">")
+		return
+	}
+	if v.options_.Renderer != nil && reflected.CanInterface() {
+		var result, handled = v.options_.Renderer(reflected.Interface())
+		if handled {
+			v.write(result)
+			return
+		}
 	}
 	switch reflected.Kind() {
 	case ref.Bool:
-		return formatBoolean(reflected, depth)
+		v.write(v.formatBoolean(reflected, depth))
 
 	case ref.Uint, ref.Uint8, ref.Uint16, ref.Uint32, ref.Uint64, ref.Uintptr:
-		return formatUnsigned(reflected, depth)
+		v.write(v.formatUnsigned(reflected, depth))
 
 	case ref.Int, ref.Int8, ref.Int16, ref.Int64:
-		return formatInteger(reflected, depth)
+		v.write(v.formatInteger(reflected, depth))
 
 	case ref.Float32, ref.Float64:
-		return formatFloat(reflected, depth)
+		v.write(v.formatFloat(reflected, depth))
 
 	case ref.Complex64, ref.Complex128:
-		return formatComplex(reflected, depth)
+		v.write(v.formatComplex(reflected, depth))
 
 	case ref.Int32:
-		return formatRune(reflected, depth)
+		v.write(v.formatRune(reflected, depth))
 
 	case ref.String:
-		return formatString(reflected, depth)
+		v.write(v.formatString(reflected, depth))
 
 	case ref.Func:
-		return formatFunction(reflected, depth)
+		v.write(v.formatFunction(reflected, depth))
 
 	case ref.Chan:
-		return formatChannel(reflected, depth)
+		v.formatChannel(reflected, depth)
 
 	case ref.Array, ref.Slice:
-		return formatArray(reflected, depth)
+		v.formatArray(reflected, depth)
 
 	case ref.Map:
-		return formatMap(reflected, depth)
+		v.formatMap(reflected, depth)
 
 	case ref.Struct:
-		return formatStructure(reflected, depth)
+		v.formatStructure(reflected, depth)
 
 	case ref.Pointer:
-		return formatPointer(reflected, depth)
+		v.formatPointer(reflected, depth)
 
 	case ref.Interface:
-		return formatInterface(reflected, depth)
+		v.formatInterface(reflected, depth)
 
 	case ref.UnsafePointer:
-		return formatUnsafe(reflected, depth)
+		v.write(v.formatUnsafe(reflected, depth))
 
 	default:
 		var message = fmt.Sprintf(
@@ -1104,4 +3607,301 @@ func formatValue(
 		)
 		panic(message)
 	}
+	if v.options_.AnnotateStringer {
+		v.annotateStringer(reflected)
+	}
+}
+
+func hasMixedKinds(
+	keys []ref.Value,
+) bool {
+	var kinds = make(map[ref.Kind]bool)
+	for _, key := range keys {
+		if key.Kind() == ref.Interface {
+			key = key.Elem()
+		}
+		kinds[key.Kind()] = true
+	}
+	return len(kinds) > 1
+}
+
+func isAnnotating() bool {
+	// NOTE: The calling goroutine is annotating a value if a String() method
+	// called by the annotateStringer method is on its call stack.  Checking the
+	// call stack rather than shared state keeps concurrent goroutines that
+	// format the same types from interfering with each other.  The whole call
+	// stack is checked since a String() method may call arbitrarily deeply
+	// nested functions before formatting its value.
+	var counters = make([]uintptr, 64)
+	var count = run.Callers(2, counters)
+	for count == len(counters) {
+		counters = make([]uintptr, 2*len(counters))
+		count = run.Callers(2, counters)
+	}
+	var frames = run.CallersFrames(counters[:count])
+	for {
+		var frame, more = frames.Next()
+		if frame.Function == annotatingFunction {
+			return true
+		}
+		if !more {
+			return false
+		}
+	}
+}
+
+func (v *formatter_) isCompact(
+	elements []string,
+) bool {
+	if !v.options_.CompactArrays {
+		return false
+	}
+	var size = v.options_.CompactSize
+	if size == 0 {
+		size = defaultCompactSize
+	}
+	var width = v.options_.CompactWidth
+	if width == 0 {
+		width = defaultCompactWidth
+	}
+	if uint(len(elements)) > size {
+		return false
+	}
+	var total = 2 * (len(elements) - 1) // The ", " separators.
+	for _, element := range elements {
+		if sts.Contains(element, "\n") {
+			return false
+		}
+		total += utf.RuneCountInString(element)
+	}
+	return uint(total) <= width
+}
+
+func (v *formatter_) write(
+	text string,
+) {
+	var _, err = iox.WriteString(v.writer_, text)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func joinWords(
+	words []string,
+	style string,
+) string {
+	var result string
+	switch style {
+	case "lower", "camel":
+		for index, word := range words {
+			if index == 0 {
+				result += sts.ToLower(word)
+			} else {
+				result += capitalizeWord(word)
+			}
+		}
+	case "upper":
+		for _, word := range words {
+			result += capitalizeWord(word)
+		}
+	case "snake":
+		result = sts.ToLower(sts.Join(words, "-"))
+	case "allcaps":
+		result = sts.ToUpper(sts.Join(words, "_"))
+	case "title":
+		for index, word := range words {
+			if index > 0 {
+				result += " "
+			}
+			result += capitalizeWord(word)
+		}
+	case "dot":
+		result = sts.ToLower(sts.Join(words, "."))
+	default:
+		var message = fmt.Sprintf(
+			"Attempted to use an unknown case style: %q",
+			style,
+		)
+		panic(message)
+	}
+	return result
+}
+
+func lessKey(
+	firstKey ref.Value,
+	secondKey ref.Value,
+) bool {
+	// NOTE:
+	// The keys are ordered by their kinds first and then by their values using
+	// the criteria described in the formatMap function above.
+
+	// Convert wrapper types into their element types.
+	if firstKey.Kind() == ref.Interface {
+		firstKey = firstKey.Elem()
+	}
+	if secondKey.Kind() == ref.Interface {
+		secondKey = secondKey.Elem()
+	}
+	// Sort by key type if the keys have different types.
+	if firstKey.Kind() != secondKey.Kind() {
+		var firstType = typeMap[firstKey.Kind()]
+		var secondType = typeMap[secondKey.Kind()]
+		return firstType < secondType
+	}
+	// Sort by key value if they have the same type.
+	switch firstKey.Kind() {
+	case ref.Bool:
+		return !(firstKey.Bool()) && secondKey.Bool()
+	case ref.Int, ref.Int8, ref.Int16, ref.Int32, ref.Int64:
+		return firstKey.Int() < secondKey.Int()
+	case ref.Uint, ref.Uint8, ref.Uint16, ref.Uint32, ref.Uint64:
+		return firstKey.Uint() < secondKey.Uint()
+	case ref.Float32, ref.Float64:
+		return firstKey.Float() < secondKey.Float()
+	case ref.Complex64, ref.Complex128:
+		var firstAmplitude = cmp.Abs(firstKey.Complex())
+		var secondAmplitude = cmp.Abs(secondKey.Complex())
+		return firstAmplitude < secondAmplitude
+	case ref.String:
+		return firstKey.String() < secondKey.String()
+	default:
+		// Sort any other comparable keys by their formatted strings.
+		return formatKey(firstKey) < formatKey(secondKey)
+	}
+}
+
+func nullableValue(
+	reflected ref.Value,
+) (
+	value ref.Value,
+	isNullable bool,
+) {
+	// A nullable structure has a bool "Valid" field and one other exported
+	// field.  The other field is returned only if the structure is valid.
+	var reflectedType = reflected.Type()
+	var valid, exists = reflectedType.FieldByName("Valid")
+	if !exists || valid.Type.Kind() != ref.Bool || len(valid.Index) > 1 {
+		return
+	}
+	var others []int
+	for index := 0; index < reflectedType.NumField(); index++ {
+		var field = reflectedType.Field(index)
+		if field.IsExported() && field.Name != "Valid" {
+			others = append(others, index)
+		}
+	}
+	if len(others) != 1 {
+		return
+	}
+	isNullable = true
+	if reflected.FieldByIndex(valid.Index).Bool() {
+		value = reflected.Field(others[0])
+	}
+	return
+}
+
+func (v *formatter_) omit(
+	placeholder string,
+) {
+	v.omitted_ = true
+	v.write(placeholder)
+}
+
+func relativeToCardinal(
+	ordinal int,
+	size int,
+) int {
+	// NOTE: Relative ordinals run from 1 to size for the first through last
+	// elements and from -1 to -size for the last through first elements.  The
+	// resulting cardinal index is zero based.
+	if ordinal == 0 {
+		var message = "Attempted to use a zero ordinal to index an array."
+		panic(message)
+	}
+	if ordinal < -size || ordinal > size {
+		var message = fmt.Sprintf(
+			"Attempted to use an ordinal that is out of range: %v (size %v)",
+			ordinal,
+			size,
+		)
+		panic(message)
+	}
+	if ordinal < 0 {
+		ordinal += size + 1
+	}
+	return ordinal - 1
+}
+
+func sortKeys(
+	keys []ref.Value,
+) {
+	sor.SliceStable(
+		keys,
+		func(i, j int) bool {
+			return lessKey(keys[i], keys[j])
+		},
+	)
+}
+
+func sortedKeys[K comparable, V any](
+	map_ map[K]V,
+) []K {
+	var keys = make([]K, 0, len(map_))
+	for key := range map_ {
+		keys = append(keys, key)
+	}
+	sortValues(keys)
+	return keys
+}
+
+func sortValues[V any](
+	values []V,
+) {
+	// Sort the values in place using the same criteria as for the keys of a
+	// map so that they need not be converted back from reflected values.
+	sor.SliceStable(
+		values,
+		func(i, j int) bool {
+			return lessKey(ref.ValueOf(values[i]), ref.ValueOf(values[j]))
+		},
+	)
+}
+
+func splitWords(
+	input string,
+	style string,
+) []string {
+	var words []string
+	switch style {
+	case "lower", "upper", "camel":
+		// Start a new word at each lowercase to uppercase transition.
+		var word []rune
+		var foundLower bool
+		for _, r := range input {
+			if uni.IsUpper(r) && foundLower {
+				words = append(words, string(word))
+				word = nil
+			}
+			foundLower = uni.IsLower(r) || uni.IsDigit(r)
+			word = append(word, r)
+		}
+		if len(word) > 0 {
+			words = append(words, string(word))
+		}
+	case "snake":
+		words = sts.Split(input, "-")
+	case "allcaps":
+		words = sts.Split(input, "_")
+	case "title":
+		words = sts.Fields(input)
+	case "dot":
+		words = sts.Split(input, ".")
+	default:
+		var message = fmt.Sprintf(
+			"Attempted to use an unknown case style: %q",
+			style,
+		)
+		panic(message)
+	}
+	return words
 }
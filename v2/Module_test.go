@@ -13,10 +13,18 @@
 package module_test
 
 import (
+	byt "bytes"
+	errors "errors"
 	fmt "fmt"
 	uti "github.com/craterdog/go-missing-utilities/v2"
 	ass "github.com/stretchr/testify/assert"
+	mat "math"
+	osx "os"
+	sts "strings"
+	syn "sync"
 	tes "testing"
+	tim "time"
+	utf "unicode/utf8"
 )
 
 type Integer int
@@ -151,6 +159,295 @@ func TestIsDefined(t *tes.T) {
 	ass.True(t, uti.IsDefined(slice))
 }
 
+func TestFileSystem(t *tes.T) {
+	var directory = t.TempDir() + "/nested/directory"
+	ass.False(t, uti.PathExists(directory))
+	ass.True(t, uti.EnsureDirectory(directory))
+	ass.True(t, uti.PathExists(directory))
+	ass.False(t, uti.EnsureDirectory(directory))
+
+	var filename = directory + "/file.txt"
+	uti.WriteFile(filename, "not a directory")
+	ass.PanicsWithError(
+		t,
+		"Attempted to ensure a directory where a file exists: "+filename,
+		func() { uti.EnsureDirectory(filename) },
+	)
+}
+
+func TestFileSystemSafely(t *tes.T) {
+	var directory = t.TempDir() + "/safe"
+	var filename = directory + "/file.txt"
+	var exists, err = uti.PathExistsSafely(directory)
+	ass.False(t, exists)
+	ass.Nil(t, err)
+
+	err = uti.WriteFileSafely(filename, "missing directory")
+	ass.NotNil(t, err)
+	var source string
+	source, err = uti.ReadFileSafely(filename)
+	ass.Equal(t, "", source)
+	ass.NotNil(t, err)
+
+	ass.Nil(t, uti.MakeDirectorySafely(directory))
+	ass.Nil(t, uti.WriteFileSafely(filename, "contents"))
+	source, err = uti.ReadFileSafely(filename)
+	ass.Equal(t, "contents", source)
+	ass.Nil(t, err)
+	exists, err = uti.PathExistsSafely(filename)
+	ass.True(t, exists)
+	ass.Nil(t, err)
+
+	ass.Nil(t, uti.RemakeDirectorySafely(directory))
+	exists, _ = uti.PathExistsSafely(filename)
+	ass.False(t, exists)
+	ass.Nil(t, uti.RemovePathSafely(directory))
+	exists, _ = uti.PathExistsSafely(directory)
+	ass.False(t, exists)
+}
+
+func TestFileSystemHelpersSafely(t *tes.T) {
+	var directory = t.TempDir()
+	var nested = directory + "/nested/deeper"
+	var created, err = uti.EnsureDirectorySafely(nested)
+	ass.True(t, created)
+	ass.Nil(t, err)
+	created, err = uti.EnsureDirectorySafely(nested)
+	ass.False(t, created)
+	ass.Nil(t, err)
+
+	var filename = nested + "/file.txt"
+	ass.Nil(t, uti.WriteFileForcedSafely(directory+"/forced/file.txt", "forced"))
+	ass.Nil(t, uti.WriteFileAtomicallySafely(filename, "atomic"))
+	ass.Nil(t, uti.AppendFileSafely(filename, "+appended"))
+	ass.Equal(t, "atomic+appended", uti.ReadFile(filename))
+	created, err = uti.EnsureDirectorySafely(filename)
+	ass.False(t, created)
+	ass.EqualError(t, err, "Attempted to ensure a directory where a file exists: "+filename)
+	err = uti.AppendFileSafely(directory+"/missing/file.txt", "lost")
+	ass.EqualError(t, err, "Attempted to append to a file in a missing directory: "+directory+"/missing")
+	ass.NotNil(t, uti.WriteFileAtomicallySafely(directory+"/missing/file.txt", "lost"))
+	ass.False(t, uti.PathExists(directory+"/missing"))
+
+	ass.Nil(t, uti.CopyFileSafely(filename, directory+"/copy.txt"))
+	ass.Equal(t, "atomic+appended", uti.ReadFile(directory+"/copy.txt"))
+	ass.NotNil(t, uti.CopyFileSafely(directory+"/missing.txt", directory+"/copy.txt"))
+	ass.Nil(t, uti.CopyDirectorySafely(directory+"/nested", directory+"/copied"))
+	ass.Equal(t, "atomic+appended", uti.ReadFile(directory+"/copied/deeper/file.txt"))
+	err = uti.CopyDirectorySafely(directory, directory+"/inside")
+	ass.EqualError(t, err, "Attempted to copy a directory into itself: "+directory+"/inside")
+
+	var jsonFile = directory + "/data.json"
+	ass.Nil(t, uti.WriteJSONSafely(jsonFile, map[string]int{"one": 1}))
+	var decoded map[string]int
+	ass.Nil(t, uti.ReadJSONSafely(jsonFile, &decoded))
+	ass.Equal(t, map[string]int{"one": 1}, decoded)
+	ass.NotNil(t, uti.WriteJSONSafely(jsonFile, func() {}))
+	ass.NotNil(t, uti.ReadJSONSafely(directory+"/missing.json", &decoded))
+
+	var csvFile = directory + "/data.csv"
+	var rows = [][]string{{"a", "b,c"}, {"d", "e"}}
+	ass.Nil(t, uti.WriteCSVSafely(csvFile, rows))
+	var read [][]string
+	read, err = uti.ReadCSVSafely(csvFile)
+	ass.Nil(t, err)
+	ass.Equal(t, rows, read)
+	read, err = uti.ReadCSVSafely(directory + "/missing.csv")
+	ass.Nil(t, read)
+	ass.NotNil(t, err)
+}
+
+func TestCopyFiles(t *tes.T) {
+	var source = t.TempDir() + "/source"
+	uti.MakeDirectory(source + "/nested")
+	uti.WriteFile(source+"/file.txt", "file")
+	uti.WriteFile(source+"/nested/script.sh", "#!/bin/sh")
+	ass.Nil(t, osx.Chmod(source+"/nested/script.sh", 0750))
+	ass.Nil(t, osx.Symlink("file.txt", source+"/link.txt"))
+
+	var copied = t.TempDir() + "/copied.txt"
+	uti.WriteFile(copied, "old contents")
+	uti.CopyFile(source+"/file.txt", copied)
+	ass.Equal(t, "file", uti.ReadFile(copied))
+
+	var destination = t.TempDir() + "/new/destination"
+	uti.CopyDirectory(source, destination)
+	ass.Equal(t, "file", uti.ReadFile(destination+"/file.txt"))
+	ass.Equal(t, "#!/bin/sh", uti.ReadFile(destination+"/nested/script.sh"))
+	var info, err = osx.Stat(destination + "/nested/script.sh")
+	ass.Nil(t, err)
+	ass.Equal(t, osx.FileMode(0750), info.Mode().Perm())
+	var target string
+	target, err = osx.Readlink(destination + "/link.txt")
+	ass.Nil(t, err)
+	ass.Equal(t, "file.txt", target)
+
+	uti.WriteFile(source+"/file.txt", "updated")
+	uti.CopyDirectory(source, destination)
+	ass.Equal(t, "updated", uti.ReadFile(destination+"/file.txt"))
+	ass.Panics(t, func() { uti.CopyFile(source+"/missing.txt", copied) })
+
+	var empty = t.TempDir() + "/empty"
+	uti.MakeDirectory(empty)
+	ass.PanicsWithError(
+		t,
+		"Attempted to copy a file over a directory: "+empty,
+		func() { uti.CopyFile(source+"/file.txt", empty) },
+	)
+	info, err = osx.Stat(empty)
+	ass.Nil(t, err)
+	ass.True(t, info.IsDir())
+}
+
+func TestCopyDirectoryEdgeCases(t *tes.T) {
+	var source = t.TempDir() + "/readonly"
+	uti.MakeDirectory(source + "/nested")
+	uti.WriteFile(source+"/nested/file.txt", "file")
+	ass.Nil(t, osx.Chmod(source+"/nested", 0555))
+	ass.Nil(t, osx.Chmod(source, 0555))
+	defer osx.Chmod(source, 0755)
+	defer osx.Chmod(source+"/nested", 0755)
+
+	var destination = t.TempDir() + "/copy"
+	uti.CopyDirectory(source, destination)
+	defer osx.Chmod(destination, 0755)
+	defer osx.Chmod(destination+"/nested", 0755)
+	ass.Equal(t, "file", uti.ReadFile(destination+"/nested/file.txt"))
+	var info, err = osx.Stat(destination + "/nested")
+	ass.Nil(t, err)
+	ass.Equal(t, osx.FileMode(0555), info.Mode().Perm())
+
+	ass.Panics(t, func() { uti.CopyDirectory(destination, destination+"/sub") })
+	ass.Panics(t, func() { uti.CopyDirectory(destination, destination) })
+	ass.False(t, uti.PathExists(destination+"/sub"))
+}
+
+func TestCSV(t *tes.T) {
+	var filename = t.TempDir() + "/table.csv"
+	var rows = [][]string{
+		{"name", "address", "quote"},
+		{"Jane", "1 Main St, Springfield", `She said "hi"`},
+		{"John", "", "multiple\nlines"},
+	}
+	uti.WriteCSV(filename, rows)
+	ass.Contains(t, uti.ReadFile(filename), `"1 Main St, Springfield"`)
+	ass.Equal(t, rows, uti.ReadCSV(filename))
+
+	uti.WriteFile(filename, "a,b\nc\n")
+	ass.Panics(t, func() { uti.ReadCSV(filename) })
+}
+
+type Configuration struct {
+	Name    string         `json:"name"`
+	Port    int            `json:"port"`
+	Enabled bool           `json:"enabled"`
+	Tags    []string       `json:"tags"`
+	Limits  map[string]int `json:"limits"`
+}
+
+func TestWriteFileForced(t *tes.T) {
+	var directory = t.TempDir() + "/nested/directories"
+	var filename = directory + "/file.txt"
+	ass.Panics(t, func() { uti.WriteFile(filename, "strict") })
+	ass.False(t, uti.PathExists(directory))
+	uti.WriteFileForced(filename, "forced")
+	ass.Equal(t, "forced", uti.ReadFile(filename))
+	uti.WriteFileForced(filename, "again")
+	ass.Equal(t, "again", uti.ReadFile(filename))
+}
+
+func TestWriteFileAtomically(t *tes.T) {
+	var directory = t.TempDir()
+	var filename = directory + "/config.txt"
+	uti.WriteFileAtomically(filename, "first")
+	ass.Equal(t, "first", uti.ReadFile(filename))
+	uti.WriteFileAtomically(filename, "second")
+	ass.Equal(t, "second", uti.ReadFile(filename))
+	var info, err = osx.Stat(filename)
+	ass.Nil(t, err)
+	ass.Equal(t, osx.FileMode(0644), info.Mode().Perm())
+
+	// Renaming over a directory fails so the temporary file must be removed.
+	var blocked = directory + "/blocked"
+	uti.MakeDirectory(blocked + "/child")
+	ass.Panics(t, func() { uti.WriteFileAtomically(blocked, "blocked") })
+	var entries []osx.DirEntry
+	entries, err = osx.ReadDir(directory)
+	ass.Nil(t, err)
+	ass.Equal(t, 2, len(entries))
+	ass.Panics(t, func() { uti.WriteFileAtomically(directory+"/missing/file", "") })
+}
+
+func TestAppendFile(t *tes.T) {
+	var directory = t.TempDir()
+	var filename = directory + "/log.txt"
+	uti.AppendFile(filename, "first\n")
+	uti.AppendFile(filename, "second\n")
+	ass.Equal(t, "first\nsecond\n", uti.ReadFile(filename))
+
+	var missing = directory + "/missing/log.txt"
+	ass.PanicsWithError(
+		t,
+		"Attempted to append to a file in a missing directory: "+directory+"/missing",
+		func() { uti.AppendFile(missing, "lost") },
+	)
+}
+
+func TestJoinPath(t *tes.T) {
+	ass.Equal(t, "foo/bar", uti.JoinPath("foo", "bar"))
+	ass.Equal(t, "/home/user/foo/bar", uti.JoinPath("/home/user/", "foo", "bar"))
+	ass.Equal(t, "/home/user/foo/bar", uti.JoinPath("/home/user/", "/foo/", "/bar/"))
+	ass.Equal(t, "/home/user/foo", uti.JoinPath("//home//user//", "", "foo"))
+	ass.Equal(t, "foo/bar.go", uti.JoinPath("", "foo/", "bar.go"))
+	ass.Equal(t, "/", uti.JoinPath("/"))
+	ass.Equal(t, "", uti.JoinPath())
+}
+
+func TestBaseAndDirNames(t *tes.T) {
+	ass.Equal(t, "c.go", uti.BaseName("a/b/c.go"))
+	ass.Equal(t, "a/b", uti.DirName("a/b/c.go"))
+	ass.Equal(t, "b", uti.BaseName("a/b/"))
+	ass.Equal(t, "a", uti.DirName("a/b/"))
+	ass.Equal(t, "a", uti.BaseName("/a"))
+	ass.Equal(t, "/", uti.DirName("/a"))
+	ass.Equal(t, "/", uti.BaseName("/"))
+	ass.Equal(t, "/", uti.DirName("/"))
+	ass.Equal(t, "a", uti.BaseName("a"))
+	ass.Equal(t, ".", uti.DirName("a"))
+}
+
+func TestFileExtension(t *tes.T) {
+	ass.Equal(t, ".go", uti.FileExtension("src/Module.go"))
+	ass.Equal(t, "src/Module", uti.StripExtension("src/Module.go"))
+	ass.Equal(t, ".gz", uti.FileExtension("archive.tar.gz"))
+	ass.Equal(t, "archive.tar", uti.StripExtension("archive.tar.gz"))
+	ass.Equal(t, "", uti.FileExtension("bin/Makefile"))
+	ass.Equal(t, "bin/Makefile", uti.StripExtension("bin/Makefile"))
+	ass.Equal(t, "", uti.FileExtension("home/.profile"))
+	ass.Equal(t, "home/.profile", uti.StripExtension("home/.profile"))
+	ass.Equal(t, "", uti.FileExtension("v1.2/README"))
+	ass.Equal(t, "v1.2/README", uti.StripExtension("v1.2/README"))
+}
+
+func TestJSON(t *tes.T) {
+	var filename = t.TempDir() + "/configuration.json"
+	var expected = Configuration{
+		Name:    "server",
+		Port:    8080,
+		Enabled: true,
+		Tags:    []string{"alpha", "beta"},
+		Limits:  map[string]int{"connections": 100},
+	}
+	uti.WriteJSON(filename, expected)
+	ass.Contains(t, uti.ReadFile(filename), `    "name": "server",`)
+	var actual Configuration
+	uti.ReadJSON(filename, &actual)
+	ass.Equal(t, expected, actual)
+
+	uti.WriteFile(filename, "{invalid")
+	ass.Panics(t, func() { uti.ReadJSON(filename, &actual) })
+}
+
 var booleanFalse = false
 var booleanTrue = true
 var byte16 = byte(16)
@@ -162,6 +459,21 @@ var complex4 = 4 + 0i
 var complex5i = 5i
 var stringHello = "Hello World!"
 
+func TestEnvironment(t *tes.T) {
+	var name = "MISSING_UTILITIES_TEST_VARIABLE"
+	t.Setenv(name, "value")
+	ass.Equal(t, "value", uti.GetEnvironment(name, "fallback"))
+	ass.Equal(t, "value", uti.RequireEnvironment(name))
+
+	t.Setenv(name, "")
+	ass.Equal(t, "fallback", uti.GetEnvironment(name, "fallback"))
+	ass.Equal(t, "", uti.RequireEnvironment(name))
+
+	osx.Unsetenv(name)
+	ass.Equal(t, "fallback", uti.GetEnvironment(name, "fallback"))
+	ass.Panics(t, func() { uti.RequireEnvironment(name) })
+}
+
 func TestPrimitives(t *tes.T) {
 	fmt.Println("Primitives")
 	fmt.Println(uti.Format(booleanFalse))
@@ -189,6 +501,156 @@ func TestPrimitives(t *tes.T) {
 	fmt.Println()
 }
 
+func TestSpecialFloats(t *tes.T) {
+	ass.Equal(t, "NaN(float64)", uti.Format(mat.NaN()))
+	ass.Equal(t, "+Inf(float64)", uti.Format(mat.Inf(1)))
+	ass.Equal(t, "-Inf(float64)", uti.Format(mat.Inf(-1)))
+	ass.Equal(t, "NaN(float32)", uti.Format(float32(mat.NaN())))
+}
+
+func TestFloats(t *tes.T) {
+	ass.Equal(t, "10.0", uti.Format(10.0))
+	ass.Equal(t, "-10.0", uti.Format(-10.0))
+	ass.Equal(t, "0.0", uti.Format(0.0))
+	ass.Equal(t, "1.5", uti.Format(1.5))
+	ass.Equal(t, "1E+10", uti.Format(1e10))
+	ass.Equal(t, "1.23E+10", uti.Format(float))
+	ass.Equal(t, "1E-10", uti.Format(1e-10))
+	ass.Equal(t, "1.1", uti.Format(float32(1.1)))
+}
+
+func TestPolarComplex(t *tes.T) {
+	ass.Equal(t, "(0+5i)", uti.Format(complex5i))
+	var options = uti.FormatOptions{
+		PolarComplex: true,
+	}
+	ass.Equal(t, "(5∠1.5707963267948966)", uti.FormatWithOptions(complex5i, options))
+	ass.Equal(t, "(4∠0)", uti.FormatWithOptions(complex4, options))
+}
+
+func TestPrintableBytes(t *tes.T) {
+	var letter = byte('A')
+	ass.Equal(t, "0x41", uti.Format(letter))
+	var options = uti.FormatOptions{
+		PrintableBytes: true,
+	}
+	ass.Equal(t, "'A'", uti.FormatWithOptions(letter, options))
+	ass.Equal(t, "0x7", uti.FormatWithOptions(byte(7), options))
+	ass.Equal(t, "0x41", uti.FormatWithOptions(uint16(letter), options))
+}
+
+func TestFormatTo(t *tes.T) {
+	var values = []any{
+		booleanTrue,
+		int13,
+		stringHello,
+		[]int{1, 2, 3},
+		map[string]int{"one": 1, "two": 2},
+		structure,
+		CreateFooBar(42, "the answer"),
+		map_,
+	}
+	for _, value := range values {
+		var buffer byt.Buffer
+		uti.FormatTo(&buffer, value)
+		ass.Equal(t, uti.Format(value), buffer.String())
+	}
+}
+
+func TestFormatTabbed(t *tes.T) {
+	var nested = map[string][]int{
+		"first": {1, 2},
+	}
+	var expected = "[\n\t\"first\": [\n\t\t1\n\t\t2\n\t](array[int])\n](map[string, array[int]])"
+	ass.Equal(t, expected, uti.FormatTabbed(nested))
+	var options = uti.FormatOptions{
+		Indentation: "  ",
+	}
+	expected = "[\n  \"first\": [\n    1\n    2\n  ](array[int])\n](map[string, array[int]])"
+	ass.Equal(t, expected, uti.FormatWithOptions(nested, options))
+}
+
+func TestFormatWithRenderer(t *tes.T) {
+	var render = func(value any) (string, bool) {
+		var integer, ok = value.(int)
+		if ok {
+			return fmt.Sprintf("#%d", integer), true
+		}
+		return "", false
+	}
+	var values = []any{1, "two", 3}
+	var expected = `[
+    #1
+    "two"
+    #3
+](array[any])`
+	ass.Equal(t, expected, uti.FormatWithRenderer(values, render))
+	ass.Equal(t, "#13", uti.FormatWithRenderer(int13, render))
+	ass.Equal(t, "0x5", uti.FormatWithRenderer(uint85, render))
+}
+
+func TestGroupMapKeys(t *tes.T) {
+	var mixed = map[any]any{
+		"two":  2,
+		true:   "yes",
+		int13:  "thirteen",
+		"one":  1,
+		false:  "no",
+		uint85: "five",
+	}
+	var options = uti.FormatOptions{
+		GroupMapKeys: true,
+	}
+	var expected = `[
+    // bool
+    false: "no"
+    true: "yes"
+    // uint8
+    0x5: "five"
+    // int
+    13: "thirteen"
+    // string
+    "one": 1
+    "two": 2
+](map[any, any])`
+	ass.Equal(t, expected, uti.FormatWithOptions(mixed, options))
+
+	var homogeneous = map[string]int{"one": 1, "two": 2}
+	ass.Equal(t, uti.Format(homogeneous), uti.FormatWithOptions(homogeneous, options))
+}
+
+func TestFormatBounded(t *tes.T) {
+	var large = make([]int, 1000)
+	var full = uti.Format(large)
+	ass.Equal(t, full, uti.FormatBounded(large, uint(len(full))))
+
+	var bounded = uti.FormatBounded(large, 100)
+	ass.LessOrEqual(t, len(bounded), 100)
+	ass.True(t, sts.HasSuffix(bounded, "…(truncated)"))
+	ass.True(t, sts.HasPrefix(full, sts.TrimSuffix(bounded, "…(truncated)")))
+
+	var text = sts.Repeat("世界", 20)
+	bounded = uti.FormatBounded(text, 30)
+	ass.LessOrEqual(t, len(bounded), 30)
+	ass.True(t, utf.ValidString(bounded))
+
+	var alphabet = "abcdefghijklmnopqrstuvwxyz"
+	for bound := uint(0); bound <= 5; bound++ {
+		bounded = uti.FormatBounded(alphabet, bound)
+		ass.LessOrEqual(t, len(bounded), int(bound))
+		ass.True(t, utf.ValidString(bounded))
+	}
+	ass.Equal(t, "", uti.FormatBounded(alphabet, 2))
+	ass.Equal(t, "…(t", uti.FormatBounded(alphabet, 5))
+}
+
+func TestFormatWithTiming(t *tes.T) {
+	var large = make([]int, 1000)
+	var result, duration = uti.FormatWithTiming(large)
+	ass.Equal(t, uti.Format(large), result)
+	ass.GreaterOrEqual(t, duration, tim.Duration(0))
+}
+
 func TestIntrinsics(t *tes.T) {
 	fmt.Println("Intrinsics")
 	var integer = Integer(42)
@@ -226,6 +688,249 @@ func TestArrays(t *tes.T) {
 	fmt.Println()
 }
 
+func TestArraysAreEquivalent(t *tes.T) {
+	var first = []string{"alpha", "beta", "beta", "gamma"}
+	var second = []string{"beta", "gamma", "alpha", "beta"}
+	ass.True(t, uti.ArraysAreEquivalent(first, second))
+	ass.True(t, uti.ArraysAreEquivalent(second, first))
+	ass.False(t, uti.ArraysAreEqual(first, second))
+
+	var third = []string{"alpha", "alpha", "beta", "gamma"}
+	ass.False(t, uti.ArraysAreEquivalent(first, third))
+	ass.False(t, uti.ArraysAreEquivalent(third, first))
+	ass.False(t, uti.ArraysAreEquivalent(first, first[1:]))
+	ass.True(t, uti.ArraysAreEquivalent([]int{}, []int{}))
+}
+
+func TestCompactArrays(t *tes.T) {
+	var options = uti.FormatOptions{
+		CompactArrays: true,
+	}
+	var small = []int{1, 2, 3}
+	ass.Equal(t, "[1, 2, 3](array[int])", uti.FormatWithOptions(small, options))
+	var expected = `[
+    1
+    2
+    3
+](array[int])`
+	ass.Equal(t, expected, uti.Format(small))
+
+	var large = []int{1, 2, 3, 4, 5, 6}
+	ass.Contains(t, uti.FormatWithOptions(large, options), "\n")
+	options.CompactSize = 6
+	ass.Equal(t, "[1, 2, 3, 4, 5, 6](array[int])", uti.FormatWithOptions(large, options))
+	options.CompactWidth = 10
+	ass.Contains(t, uti.FormatWithOptions(large, options), "\n")
+
+	options = uti.FormatOptions{
+		CompactArrays: true,
+	}
+	var mapping = map[string]int{"one": 1, "two": 2}
+	expected = `["one": 1, "two": 2](map[string, int])`
+	ass.Equal(t, expected, uti.FormatWithOptions(mapping, options))
+	var nested = [][]int{{1, 2}, {3}}
+	expected = `[[1, 2](array[int]), [3](array[int])](array[array[int]])`
+	ass.Equal(t, expected, uti.FormatWithOptions(nested, options))
+}
+
+func TestChannels(t *tes.T) {
+	var array = []string{"alpha", "beta", "gamma"}
+	var channel = uti.SliceToChannel(array)
+	ass.Equal(t, array, uti.ChannelToSlice(channel))
+	ass.Equal(t, []int{}, uti.ChannelToSlice(uti.SliceToChannel([]int{})))
+
+	var source = make(chan int)
+	go func() {
+		for index := 1; index <= 3; index++ {
+			source <- index
+		}
+		close(source)
+	}()
+	ass.Equal(t, []int{1, 2, 3}, uti.ChannelToSlice(source))
+}
+
+func TestCompactArray(t *tes.T) {
+	var strings = []string{"", "alpha", "", "beta", ""}
+	ass.Equal(t, []string{"alpha", "beta"}, uti.CompactArray(strings))
+
+	var one = 1
+	var two = 2
+	var pointers = []*int{nil, &one, nil, &two}
+	ass.Equal(t, []*int{&one, &two}, uti.CompactArray(pointers))
+
+	var arrays = [][]int{nil, {1}, nil}
+	ass.Equal(t, [][]int{{1}}, uti.CompactArray(arrays))
+	ass.Equal(t, []string{}, uti.CompactArray([]string{""}))
+}
+
+type Record struct {
+	ID   int
+	Tags []string
+}
+
+func TestDistinctBy(t *tes.T) {
+	var records = []Record{
+		{1, []string{"first"}},
+		{2, []string{"second"}},
+		{1, []string{"duplicate"}},
+		{3, nil},
+		{2, nil},
+	}
+	var distinct = uti.DistinctBy(records, func(record Record) int {
+		return record.ID
+	})
+	var expected = []Record{
+		{1, []string{"first"}},
+		{2, []string{"second"}},
+		{3, nil},
+	}
+	ass.Equal(t, expected, distinct)
+	ass.Equal(t, 5, len(records))
+}
+
+func TestFlattenArray(t *tes.T) {
+	var nested = [][]int{{1, 2, 3}, {}, {4}, {5, 6}}
+	ass.Equal(t, []int{1, 2, 3, 4, 5, 6}, uti.FlattenArray(nested))
+	ass.Equal(t, []int{}, uti.FlattenArray([][]int{{}, {}}))
+	ass.Equal(t, []int{}, uti.FlattenArray([][]int{}))
+}
+
+func TestForEach(t *tes.T) {
+	var sum int
+	var indices []uint
+	uti.ForEach([]int{1, 2, 3, 4}, func(index uint, value int) {
+		indices = append(indices, index)
+		sum += value
+	})
+	ass.Equal(t, 10, sum)
+	ass.Equal(t, []uint{0, 1, 2, 3}, indices)
+}
+
+func TestBinarySearch(t *tes.T) {
+	var array = []int{1, 3, 3, 5, 7}
+	var index, found = uti.BinarySearch(array, 5)
+	ass.Equal(t, 3, index)
+	ass.True(t, found)
+	index, found = uti.BinarySearch(array, 3)
+	ass.Equal(t, 1, index)
+	ass.True(t, found)
+	index, found = uti.BinarySearch(array, 4)
+	ass.Equal(t, 3, index)
+	ass.False(t, found)
+	index, found = uti.BinarySearch(array, 0)
+	ass.Equal(t, 0, index)
+	ass.False(t, found)
+	index, found = uti.BinarySearch(array, 9)
+	ass.Equal(t, 5, index)
+	ass.False(t, found)
+	index, found = uti.BinarySearch([]string{}, "alpha")
+	ass.Equal(t, 0, index)
+	ass.False(t, found)
+}
+
+func TestInsertSorted(t *tes.T) {
+	var array = []int{2, 4, 6}
+	ass.Equal(t, []int{2, 4, 5, 6}, uti.InsertSorted(array, 5))
+	ass.Equal(t, []int{1, 2, 4, 6}, uti.InsertSorted(array, 1))
+	ass.Equal(t, []int{2, 4, 6, 8}, uti.InsertSorted(array, 8))
+	ass.Equal(t, []int{2, 4, 4, 6}, uti.InsertSorted(array, 4))
+	ass.Equal(t, []int{2, 4, 6}, array)
+	ass.Equal(t, []string{"beta"}, uti.InsertSorted([]string{}, "beta"))
+}
+
+func TestLastIndexOfValue(t *tes.T) {
+	var path = []string{"a", "/", "b", "/", "c"}
+	ass.Equal(t, 3, uti.LastIndexOfValue(path, "/"))
+	ass.Equal(t, 0, uti.LastIndexOfValue(path, "a"))
+	ass.Equal(t, -1, uti.LastIndexOfValue(path, "d"))
+	ass.Equal(t, -1, uti.LastIndexOfValue([]int{}, 1))
+}
+
+func TestRotateArray(t *tes.T) {
+	var array = []int{1, 2, 3, 4, 5}
+	ass.Equal(t, []int{3, 4, 5, 1, 2}, uti.RotateArray(array, 2))
+	ass.Equal(t, []int{4, 5, 1, 2, 3}, uti.RotateArray(array, -2))
+	ass.Equal(t, []int{2, 3, 4, 5, 1}, uti.RotateArray(array, 11))
+	ass.Equal(t, []int{5, 1, 2, 3, 4}, uti.RotateArray(array, -6))
+	ass.Equal(t, array, uti.RotateArray(array, 0))
+	ass.Equal(t, []int{1, 2, 3, 4, 5}, array)
+	ass.Equal(t, []int{7}, uti.RotateArray([]int{7}, 3))
+	ass.Equal(t, []int{}, uti.RotateArray([]int{}, 3))
+}
+
+type Player struct {
+	Name  string
+	Score int
+}
+
+func TestSortArrayByKey(t *tes.T) {
+	var players = []Player{
+		{"Alice", 3},
+		{"Bob", 1},
+		{"Carol", 3},
+		{"Dave", 2},
+		{"Eve", 1},
+	}
+	var sorted = uti.SortArrayByKey(players, func(player Player) int {
+		return player.Score
+	})
+	var expected = []Player{
+		{"Bob", 1},
+		{"Eve", 1},
+		{"Dave", 2},
+		{"Alice", 3},
+		{"Carol", 3},
+	}
+	ass.Equal(t, expected, sorted)
+	ass.Equal(t, "Alice", players[0].Name)
+}
+
+func TestSwapValues(t *tes.T) {
+	var array = []int{1, 2, 3, 4, 5}
+	uti.SwapValues(array, 1, -1)
+	ass.Equal(t, []int{5, 2, 3, 4, 1}, array)
+	uti.SwapValues(array, -2, 2)
+	ass.Equal(t, []int{5, 4, 3, 2, 1}, array)
+	uti.SwapValues(array, 3, -3)
+	ass.Equal(t, []int{5, 4, 3, 2, 1}, array)
+	ass.Panics(t, func() { uti.SwapValues(array, 0, 1) })
+	ass.Panics(t, func() { uti.SwapValues(array, 1, 6) })
+	ass.Panics(t, func() { uti.SwapValues(array, -6, 1) })
+}
+
+func TestReverseArrayInPlace(t *tes.T) {
+	var array = []int{1, 2, 3, 4, 5}
+	uti.ReverseArrayInPlace(array)
+	ass.Equal(t, []int{5, 4, 3, 2, 1}, array)
+	var even = []string{"a", "b", "c", "d"}
+	uti.ReverseArrayInPlace(even)
+	ass.Equal(t, []string{"d", "c", "b", "a"}, even)
+	var empty = []int{}
+	uti.ReverseArrayInPlace(empty)
+	ass.Equal(t, []int{}, empty)
+}
+
+func TestBytes(t *tes.T) {
+	var a = []byte{0x0f, 0xf0, 0xaa}
+	var b = []byte{0xff, 0xff, 0x55}
+	var result = uti.XORBytes(a, b)
+	ass.Equal(t, []byte{0xf0, 0x0f, 0xff}, result)
+	ass.Equal(t, a, uti.XORBytes(result, b))
+	ass.Equal(t, []byte{0x0f, 0xf0, 0xaa}, a)
+	ass.PanicsWithValue(
+		t,
+		"Attempted to XOR byte arrays of different sizes: 3 and 2",
+		func() { uti.XORBytes(a, b[:2]) },
+	)
+
+	var bytes = []byte{0x81, 0x02}
+	ass.Equal(t, []byte{0x02, 0x05}, uti.RotateLeftBytes(bytes, 1))
+	ass.Equal(t, []byte{0x02, 0x81}, uti.RotateLeftBytes(bytes, 8))
+	ass.Equal(t, []byte{0x40, 0x81}, uti.RotateLeftBytes(bytes, 15))
+	ass.Equal(t, bytes, uti.RotateLeftBytes(bytes, 16))
+	ass.Equal(t, []byte{}, uti.RotateLeftBytes([]byte{}, 3))
+}
+
 func TestMaps(t *tes.T) {
 	fmt.Println("Maps")
 	var empty = map[string]int{}
@@ -249,6 +954,250 @@ func TestMaps(t *tes.T) {
 	fmt.Println()
 }
 
+func TestMapsAreEqualIgnoring(t *tes.T) {
+	var first = map[string]string{
+		"name":      "config",
+		"requestId": "1234",
+		"timestamp": "2025-01-01",
+	}
+	var second = map[string]string{
+		"name":      "config",
+		"requestId": "5678",
+	}
+	ass.False(t, uti.MapsAreEqual(first, second))
+	ass.False(t, uti.MapsAreEqualIgnoring(first, second, []string{"requestId"}))
+	var ignore = []string{"requestId", "timestamp"}
+	ass.True(t, uti.MapsAreEqualIgnoring(first, second, ignore))
+	ass.True(t, uti.MapsAreEqualIgnoring(second, first, ignore))
+	second["name"] = "other"
+	ass.False(t, uti.MapsAreEqualIgnoring(first, second, ignore))
+}
+
+func TestDeepMergeMaps(t *tes.T) {
+	var base = map[string]any{
+		"name": "server",
+		"network": map[string]any{
+			"host": "localhost",
+			"port": 8080,
+		},
+		"tags": []string{"alpha"},
+	}
+	var override = map[string]any{
+		"network": map[string]any{
+			"port": 9090,
+		},
+		"tags": []string{"beta"},
+	}
+	var expected = map[string]any{
+		"name": "server",
+		"network": map[string]any{
+			"host": "localhost",
+			"port": 9090,
+		},
+		"tags": []string{"beta"},
+	}
+	var merged = uti.DeepMergeMaps(base, override)
+	ass.Equal(t, expected, merged)
+	ass.Equal(t, 8080, base["network"].(map[string]any)["port"])
+
+	merged["network"].(map[string]any)["host"] = "example.com"
+	ass.Equal(t, "localhost", base["network"].(map[string]any)["host"])
+
+	override = map[string]any{
+		"name": map[string]any{"first": "web"},
+	}
+	merged = uti.DeepMergeMaps(base, override)
+	ass.Equal(t, map[string]any{"first": "web"}, merged["name"])
+}
+
+func TestDiffMaps(t *tes.T) {
+	var previous = map[string]int{
+		"delta":   4,
+		"alpha":   1,
+		"charlie": 3,
+		"bravo":   2,
+		"echo":    5,
+	}
+	var current = map[string]int{
+		"alpha":   1,
+		"bravo":   20,
+		"echo":    50,
+		"foxtrot": 6,
+		"golf":    7,
+	}
+	var added, removed, changed = uti.DiffMaps(previous, current)
+	ass.Equal(t, []string{"foxtrot", "golf"}, added)
+	ass.Equal(t, []string{"charlie", "delta"}, removed)
+	ass.Equal(t, []string{"bravo", "echo"}, changed)
+
+	added, removed, changed = uti.DiffMaps(previous, previous)
+	ass.Empty(t, added)
+	ass.Empty(t, removed)
+	ass.Empty(t, changed)
+}
+
+type Point struct {
+	X int
+	Y int
+}
+
+func TestDiffMapsWithStructureKeys(t *tes.T) {
+	var previous = map[Point]int{
+		{1, 2}: 1,
+		{5, 6}: 1,
+		{9, 9}: 1,
+	}
+	var current = map[Point]int{
+		{3, 4}: 1,
+		{7, 8}: 1,
+		{9, 9}: 2,
+	}
+	var added, removed, changed = uti.DiffMaps(previous, current)
+	ass.Equal(t, []Point{{3, 4}, {7, 8}}, added)
+	ass.Equal(t, []Point{{1, 2}, {5, 6}}, removed)
+	ass.Equal(t, []Point{{9, 9}}, changed)
+
+	var arrays = map[[2]int]bool{{2, 1}: true, {1, 2}: true}
+	var arrayKeys, _, _ = uti.DiffMaps(map[[2]int]bool{}, arrays)
+	ass.Equal(t, [][2]int{{1, 2}, {2, 1}}, arrayKeys)
+}
+
+func TestDiffMapsWithNilKey(t *tes.T) {
+	var previous = map[any]int{nil: 1, "a": 2}
+	var added, removed, changed = uti.DiffMaps(previous, map[any]int{})
+	ass.Equal(t, 0, len(added))
+	ass.Equal(t, []any{nil, "a"}, removed)
+	ass.Equal(t, 0, len(changed))
+
+	var keys = uti.ReduceMap(
+		previous,
+		[]any{},
+		func(keys []any, key any, value int) []any {
+			return append(keys, key)
+		},
+	)
+	ass.Equal(t, []any{nil, "a"}, keys)
+}
+
+func TestMapContains(t *tes.T) {
+	var mapping = map[string]int{
+		"one":  1,
+		"zero": 0,
+	}
+	ass.True(t, uti.MapContainsKey(mapping, "one"))
+	ass.True(t, uti.MapContainsKey(mapping, "zero"))
+	ass.False(t, uti.MapContainsKey(mapping, "two"))
+	ass.True(t, uti.MapContainsValue(mapping, 1))
+	ass.True(t, uti.MapContainsValue(mapping, 0))
+	ass.False(t, uti.MapContainsValue(mapping, 2))
+}
+
+func TestMapFilter(t *tes.T) {
+	var scores = map[string]int{
+		"test.alpha": 90,
+		"test.beta":  40,
+		"prod.gamma": 75,
+	}
+	var tests = uti.MapFilterKeys(scores, func(key string) bool {
+		return sts.HasPrefix(key, "test.")
+	})
+	ass.Equal(t, map[string]int{"test.alpha": 90, "test.beta": 40}, tests)
+	var passing = uti.MapFilterValues(scores, func(value int) bool {
+		return value > 50
+	})
+	ass.Equal(t, map[string]int{"test.alpha": 90, "prod.gamma": 75}, passing)
+	ass.Equal(t, 3, len(scores))
+	ass.Equal(t, map[string]int{}, uti.MapFilterKeys(scores, func(string) bool {
+		return false
+	}))
+}
+
+func TestMapIsSubset(t *tes.T) {
+	var super = map[string]int{
+		"one":   1,
+		"two":   2,
+		"three": 3,
+	}
+	var sub = map[string]int{
+		"one": 1,
+		"two": 2,
+	}
+	ass.True(t, uti.MapIsSubset(sub, super))
+	ass.False(t, uti.MapIsSubset(super, sub))
+	ass.True(t, uti.MapIsSubset(super, super))
+	ass.True(t, uti.MapIsSubset(map[string]int{}, super))
+	sub["two"] = 4
+	ass.False(t, uti.MapIsSubset(sub, super))
+	var zero = map[string]int{"four": 0}
+	ass.False(t, uti.MapIsSubset(zero, super))
+}
+
+func TestGetFold(t *tes.T) {
+	var headers = map[string]string{
+		"Content-Type": "text/plain",
+		"content-type": "text/html",
+		"Accept":       "*/*",
+	}
+	var value, found = uti.GetFold(headers, "ACCEPT")
+	ass.True(t, found)
+	ass.Equal(t, "*/*", value)
+	value, found = uti.GetFold(headers, "content-type")
+	ass.True(t, found)
+	ass.Equal(t, "text/html", value)
+	value, found = uti.GetFold(headers, "CONTENT-TYPE")
+	ass.True(t, found)
+	ass.Equal(t, "text/plain", value)
+	value, found = uti.GetFold(headers, "Host")
+	ass.False(t, found)
+	ass.Equal(t, "", value)
+}
+
+func TestTransformAndReduceMap(t *tes.T) {
+	var mapping = map[string]int{
+		"one":   1,
+		"two":   2,
+		"three": 3,
+	}
+	var doubled = uti.TransformMap(mapping, func(key string, value int) string {
+		return key + "=" + fmt.Sprint(value*2)
+	})
+	var expected = map[string]string{
+		"one":   "one=2",
+		"two":   "two=4",
+		"three": "three=6",
+	}
+	ass.Equal(t, expected, doubled)
+
+	var sum = uti.ReduceMap(mapping, 0, func(total int, key string, value int) int {
+		return total + value
+	})
+	ass.Equal(t, 6, sum)
+	var order = uti.ReduceMap(mapping, "", func(keys string, key string, value int) string {
+		return keys + key + ","
+	})
+	ass.Equal(t, "one,three,two,", order)
+	ass.Equal(t, 7, uti.ReduceMap(map[string]int{}, 7, func(total int, key string, value int) int {
+		return total + value
+	}))
+}
+
+func TestTransformAndReduceMapWithStructureKeys(t *tes.T) {
+	var weights = map[Point]int{
+		{2, 1}: 3,
+		{1, 2}: 4,
+		{0, 5}: 5,
+	}
+	var sums = uti.TransformMap(weights, func(key Point, value int) int {
+		return key.X + key.Y + value
+	})
+	ass.Equal(t, map[Point]int{{2, 1}: 6, {1, 2}: 7, {0, 5}: 10}, sums)
+
+	var order = uti.ReduceMap(weights, "", func(keys string, key Point, value int) string {
+		return keys + fmt.Sprintf("(%v,%v)", key.X, key.Y)
+	})
+	ass.Equal(t, "(0,5)(1,2)(2,1)", order)
+}
+
 type Triangle struct {
 	X float64
 	Y float64
@@ -267,6 +1216,92 @@ func TestStructures(t *tes.T) {
 	fmt.Println()
 }
 
+type Base struct {
+	ID int
+}
+
+type Derived struct {
+	Base
+	Name string
+}
+
+func TestShowEmbedding(t *tes.T) {
+	var derived = Derived{
+		Base: Base{
+			ID: 7,
+		},
+		Name: "derived",
+	}
+	var expected = `[
+    Base: [
+        ID: 7
+    ](Base)
+    ID: 7
+    Name: "derived"
+](Derived)`
+	ass.Equal(t, expected, uti.Format(derived))
+	var options = uti.FormatOptions{
+		ShowEmbedding: true,
+	}
+	expected = `[
+    Base: [
+        ID: 7
+    ](Base)
+    Base.ID: 7
+    Name: "derived"
+](Derived)`
+	ass.Equal(t, expected, uti.FormatWithOptions(derived, options))
+}
+
+type Extended struct {
+	*Base
+	Name string
+}
+
+func TestFormatNilEmbeddedPointer(t *tes.T) {
+	var extended = Extended{
+		Name: "extended",
+	}
+	var expected = `[
+    Base: &[<nil>](*Base)
+    ID: <nil>
+    Name: "extended"
+](Extended)`
+	ass.Equal(t, expected, uti.Format(extended))
+	var options = uti.FormatOptions{
+		SortFields: true,
+	}
+	ass.Equal(t, expected, uti.FormatWithOptions(extended, options))
+}
+
+type NullString struct {
+	String string
+	Valid  bool
+}
+
+func TestDetectNullable(t *tes.T) {
+	var options = uti.FormatOptions{
+		DetectNullable: true,
+	}
+	var valid = NullString{
+		String: "present",
+		Valid:  true,
+	}
+	ass.Equal(t, `"present"`, uti.FormatWithOptions(valid, options))
+	var null = NullString{}
+	ass.Equal(t, "<null>", uti.FormatWithOptions(null, options))
+	var expected = `[
+    String: ""
+    Valid: false
+](NullString)`
+	ass.Equal(t, expected, uti.Format(null))
+	var triangle = Triangle{
+		X: 3.0,
+		Y: 4.0,
+	}
+	ass.Equal(t, uti.Format(triangle), uti.FormatWithOptions(triangle, options))
+}
+
 type Structured interface {
 	GetValue() int
 }
@@ -303,6 +1338,297 @@ func TestPointers(t *tes.T) {
 	fmt.Println()
 }
 
+func TestInlineGetters(t *tes.T) {
+	var intrinsic = Intrinsic(3)
+	var expected = `&[
+    Value: 3
+](*Intrinsic)`
+	ass.Equal(t, expected, uti.Format(&intrinsic))
+
+	var options = uti.FormatOptions{
+		InlineGetters: true,
+	}
+	ass.Equal(t, "&[3](*Intrinsic)", uti.FormatWithOptions(&intrinsic, options))
+	ass.Equal(t, "3", uti.FormatWithOptions(intrinsic, options))
+	var class = CreateFooBar(42, "the answer")
+	ass.Equal(t, uti.Format(class), uti.FormatWithOptions(class, options))
+}
+
+type Described struct {
+	Name string
+}
+
+func (v Described) String() string {
+	var options = uti.FormatOptions{
+		AnnotateStringer: true,
+	}
+	return "described " + uti.FormatWithOptions(v.Name, options)
+}
+
+type Recursive struct {
+	Name string
+}
+
+func (v Recursive) String() string {
+	var options = uti.FormatOptions{
+		AnnotateStringer: true,
+	}
+	return uti.FormatWithOptions(v, options)
+}
+
+func TestAnnotateStringer(t *tes.T) {
+	var polar = CreatePolar(5, 0.5)
+	var options = uti.FormatOptions{
+		AnnotateStringer: true,
+	}
+	var expected = `[
+    amplitude: <private>
+    phase: <private>
+](Polar)`
+	ass.Equal(t, expected, uti.Format(*polar))
+	ass.Equal(t, expected+" // (5e^0.5i)", uti.FormatWithOptions(*polar, options))
+	ass.Equal(t, "&[\n](*Polar) // (5e^0.5i)", uti.FormatWithOptions(polar, options))
+
+	var described = []Described{{"alpha"}}
+	expected = `[
+    [
+        Name: "alpha"
+    ](Described) // described "alpha"
+](array[Described])`
+	ass.Equal(t, expected, uti.FormatWithOptions(described, options))
+
+	var recursive = Recursive{"beta"}
+	expected = `[
+    Name: "beta"
+](Recursive)`
+	ass.Equal(t, expected+" // "+expected, uti.FormatWithOptions(recursive, options))
+}
+
+type Nested struct {
+	Depth int
+}
+
+func (v Nested) String() string {
+	return v.nest(v.Depth)
+}
+
+func (v Nested) nest(depth int) string {
+	if depth > 0 {
+		return v.nest(depth - 1)
+	}
+	var options = uti.FormatOptions{
+		AnnotateStringer: true,
+	}
+	return uti.FormatWithOptions(v, options)
+}
+
+func TestAnnotateStringerDeeply(t *tes.T) {
+	// The String() method formats its value after more nested calls than
+	// would fit in a fixed size window of the call stack.
+	var options = uti.FormatOptions{
+		AnnotateStringer: true,
+	}
+	var nested = Nested{1000}
+	var expected = `[
+    Depth: 1000
+](Nested)`
+	ass.Equal(t, expected+" // "+expected, uti.FormatWithOptions(nested, options))
+}
+
+type Guarded struct {
+	Mutex   syn.Mutex
+	Lock    *syn.RWMutex
+	Cache   syn.Map
+	Once    syn.Once
+	Group   syn.WaitGroup
+	Counter int
+}
+
+func TestSyncTypes(t *tes.T) {
+	var guarded = &Guarded{
+		Lock:    &syn.RWMutex{},
+		Counter: 5,
+	}
+	guarded.Mutex.Lock()
+	defer guarded.Mutex.Unlock()
+	guarded.Cache.Store("key", "value")
+	var expected = `[
+    Mutex: <sync.Mutex>
+    Lock: &[<sync.RWMutex>](*RWMutex)
+    Cache: <sync.Map>
+    Once: <sync.Once>
+    Group: <sync.WaitGroup>
+    Counter: 5
+](Guarded)`
+	ass.Equal(t, "&["+expected+"](*Guarded)", uti.Format(guarded))
+	ass.Equal(t, "&[<sync.Mutex>](*Mutex)", uti.Format(&guarded.Mutex))
+}
+
+type Counted struct {
+	Mutex syn.Mutex
+	Count int
+}
+
+func (v *Counted) String() string {
+	v.Mutex.Lock()
+	defer v.Mutex.Unlock()
+	return fmt.Sprintf("counted %v", v.Count)
+}
+
+type Tally struct {
+	Counted Counted
+}
+
+func TestSyncTypesWithOptions(t *tes.T) {
+	var rendered []string
+	var options = uti.FormatOptions{
+		Renderer: func(value any) (string, bool) {
+			rendered = append(rendered, fmt.Sprintf("%T", value))
+			return "", false
+		},
+	}
+	var guarded = &Guarded{
+		Lock: &syn.RWMutex{},
+	}
+	uti.FormatWithOptions(guarded, options)
+	for _, typeName := range rendered {
+		ass.False(t, sts.HasPrefix(typeName, "sync."), typeName)
+	}
+
+	// The String() method has a pointer receiver, so annotating the field
+	// would require copying its lock.
+	var tally = &Tally{
+		Counted: Counted{
+			Count: 3,
+		},
+	}
+	options = uti.FormatOptions{
+		AnnotateStringer: true,
+	}
+	var expected = `&[[
+    Counted: [
+        Mutex: <sync.Mutex>
+        Count: 3
+    ](Counted)
+](Tally)](*Tally)`
+	ass.Equal(t, expected, uti.FormatWithOptions(tally, options))
+}
+
+func TestAnnotateStringerConcurrently(t *tes.T) {
+	var options = uti.FormatOptions{
+		AnnotateStringer: true,
+	}
+	var recursive = Recursive{"gamma"}
+	var expected = uti.FormatWithOptions(recursive, options)
+	var group syn.WaitGroup
+	for index := 0; index < 20; index++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			for iteration := 0; iteration < 20; iteration++ {
+				ass.Equal(t, expected, uti.FormatWithOptions(recursive, options))
+			}
+		}()
+	}
+	group.Wait()
+}
+
+type Aliased struct {
+	First  *int
+	Second *int
+	Third  *int
+}
+
+func TestShowAddresses(t *tes.T) {
+	var shared = 5
+	var other = 5
+	var aliased = Aliased{
+		First:  &shared,
+		Second: &shared,
+		Third:  &other,
+	}
+	var formatted = uti.Format(aliased)
+	ass.NotContains(t, formatted, "@0x")
+
+	var options = uti.FormatOptions{
+		ShowAddresses: true,
+	}
+	formatted = uti.FormatWithOptions(aliased, options)
+	var address = fmt.Sprintf("](*int)@%p", &shared)
+	ass.Equal(t, 2, sts.Count(formatted, address))
+	ass.Contains(t, formatted, fmt.Sprintf("](*int)@%p", &other))
+	ass.NotEqual(t, fmt.Sprintf("%p", &shared), fmt.Sprintf("%p", &other))
+}
+
+type Node struct {
+	Name  string
+	Owner any
+}
+
+func TestCycles(t *tes.T) {
+	var node = &Node{
+		Name: "root",
+	}
+	node.Owner = node
+	var expected = `&[[
+    Name: "root"
+    Owner: <cycle>
+](Node)](*Node)`
+	ass.Equal(t, expected, uti.Format(node))
+
+	var shared = &Node{
+		Name: "shared",
+	}
+	var nodes = []*Node{shared, shared}
+	ass.NotContains(t, uti.Format(nodes), "<cycle>")
+}
+
+type Holder struct {
+	First  int
+	Target *int
+}
+
+func TestCyclesSharingAddresses(t *tes.T) {
+	var holder = &Holder{
+		First: 5,
+	}
+	holder.Target = &holder.First
+	var expected = `&[[
+    First: 5
+    Target: &[5](*int)
+](Holder)](*Holder)`
+	ass.Equal(t, expected, uti.Format(holder))
+}
+
+type Unsorted struct {
+	Zulu  int
+	Alpha string
+	Mike  bool
+}
+
+func TestSortFields(t *tes.T) {
+	var unsorted = Unsorted{
+		Zulu:  26,
+		Alpha: "a",
+		Mike:  true,
+	}
+	var expected = `[
+    Zulu: 26
+    Alpha: "a"
+    Mike: true
+](Unsorted)`
+	ass.Equal(t, expected, uti.Format(unsorted))
+	expected = `[
+    Alpha: "a"
+    Mike: true
+    Zulu: 26
+](Unsorted)`
+	var options = uti.FormatOptions{
+		SortFields: true,
+	}
+	ass.Equal(t, expected, uti.FormatWithOptions(unsorted, options))
+}
+
 const template = `
 	<mixedName>
 	<mixedName_>
@@ -394,6 +1720,391 @@ func TestStrings(t *tes.T) {
 	ass.Equal(t, "knives", plural)
 }
 
+func TestReplaceAllFromMapRecursive(t *tes.T) {
+	var substitutions = map[string]string{
+		"greeting": "Hello <name>",
+		"name":     "<first> <last>",
+		"first":    "Jane",
+		"last":     "Doe",
+	}
+	var template = "<greeting>, welcome to <~place>!"
+	substitutions["place"] = "CraterDog"
+	var actual = uti.ReplaceAllFromMapRecursive(template, substitutions)
+	ass.Equal(t, "Hello Jane Doe, welcome to craterDog!", actual)
+
+	var cyclic = map[string]string{
+		"name": "<name>!",
+	}
+	ass.Panics(t, func() { uti.ReplaceAllFromMapRecursive("<name>", cyclic) })
+}
+
+func TestDedent(t *tes.T) {
+	var tabbed = "\t\tfirst\n\t\t\tsecond\n\n\t\tthird\n"
+	ass.Equal(t, "first\n\tsecond\n\nthird\n", uti.Dedent(tabbed))
+
+	var spaced = `
+    func main() {
+        fmt.Println("Hello")
+    }
+  `
+	var expected = `
+func main() {
+    fmt.Println("Hello")
+}
+`
+	ass.Equal(t, expected, uti.Dedent(spaced))
+	ass.Equal(t, "", uti.Dedent(""))
+}
+
+func TestIndent(t *tes.T) {
+	var block = "first\n\nthird"
+	var expected = "    first\n\n    third"
+	ass.Equal(t, expected, uti.Indent(block, "    "))
+	ass.Equal(t, block, uti.Dedent(uti.Indent(block, "\t")))
+}
+
+func TestInterpolate(t *tes.T) {
+	var values = map[string]any{
+		"name":    "Jane",
+		"count":   3,
+		"ratio":   0.5,
+		"enabled": true,
+		"tags":    []string{"a"},
+	}
+	var template = "${name} has ${count} items (${ratio}, ${enabled}) ${unknown} ${tags}"
+	var expected = `Jane has 3 items (0.5, true) ${unknown} [
+    "a"
+](array[string])`
+	ass.Equal(t, expected, uti.Interpolate(template, values))
+	ass.Equal(t, "$name ${name", uti.Interpolate("$name ${name", values))
+	ass.Equal(t, "${} Jane", uti.Interpolate("${} ${name}", values))
+}
+
+func TestIsBlank(t *tes.T) {
+	ass.True(t, uti.IsBlank(""))
+	ass.True(t, uti.IsBlank(" \t\n"))
+	ass.True(t, uti.IsBlank("\u00a0\u2003"))
+	ass.False(t, uti.IsBlank(" x "))
+	ass.False(t, uti.IsNotBlank(""))
+	ass.False(t, uti.IsNotBlank(" \t\n"))
+	ass.True(t, uti.IsNotBlank(" x "))
+	ass.True(t, uti.IsDefined(" "))
+}
+
+func TestIsNumeric(t *tes.T) {
+	ass.True(t, uti.IsInteger("42"))
+	ass.True(t, uti.IsInteger("-7"))
+	ass.False(t, uti.IsInteger("3.14"))
+	ass.False(t, uti.IsInteger("seven"))
+	ass.False(t, uti.IsInteger(""))
+
+	ass.True(t, uti.IsNumeric("42"))
+	ass.True(t, uti.IsNumeric("-3.14"))
+	ass.True(t, uti.IsNumeric("6.02e23"))
+	ass.False(t, uti.IsNumeric("1.2.3"))
+	ass.False(t, uti.IsNumeric("NaN"))
+	ass.False(t, uti.IsNumeric("-Inf"))
+	ass.False(t, uti.IsNumeric("1e400"))
+	ass.False(t, uti.IsNumeric(""))
+}
+
+func TestLongestCommon(t *tes.T) {
+	var paths = []string{
+		"/home/user/project/main.go",
+		"/home/user/project/module.go",
+		"/home/user/other/main.go",
+	}
+	ass.Equal(t, "/home/user/", uti.LongestCommonPrefix(paths))
+	ass.Equal(t, ".go", uti.LongestCommonSuffix(paths))
+	ass.Equal(t, "/main.go", uti.LongestCommonSuffix([]string{paths[0], paths[2]}))
+
+	var disjoint = []string{"alpha", "beta", "gamma", "pi"}
+	ass.Equal(t, "", uti.LongestCommonPrefix(disjoint))
+	ass.Equal(t, "", uti.LongestCommonSuffix(disjoint))
+	ass.Equal(t, "", uti.LongestCommonPrefix(nil))
+	ass.Equal(t, "", uti.LongestCommonSuffix([]string{}))
+
+	var accented = []string{"résumé", "résister"}
+	ass.Equal(t, "rés", uti.LongestCommonPrefix(accented))
+	ass.Equal(t, "é", uti.LongestCommonSuffix([]string{"café", "résumé"}))
+}
+
+func TestNormalizeString(t *tes.T) {
+	var decomposed = "Cafe\u0301"
+	var precomposed = "Caf\u00e9"
+	ass.NotEqual(t, decomposed, precomposed)
+	ass.Equal(t, precomposed, uti.NormalizeString(decomposed))
+	ass.Equal(t, precomposed, uti.NormalizeString(precomposed))
+	ass.Equal(t, "CAFÉ", uti.MakeAllCaps(uti.NormalizeString(decomposed)))
+}
+
+func TestReverseString(t *tes.T) {
+	ass.Equal(t, "olleh", uti.ReverseString("hello"))
+	ass.Equal(t, "界世 ,éfac", uti.ReverseString("café, 世界"))
+	ass.Equal(t, "", uti.ReverseString(""))
+}
+
+func TestRuneCount(t *tes.T) {
+	ass.Equal(t, uint(5), uti.RuneCount("hello"))
+	ass.Equal(t, 5, len("hello"))
+	var multibyte = "café 世界"
+	ass.Equal(t, uint(7), uti.RuneCount(multibyte))
+	ass.Equal(t, 12, len(multibyte))
+	ass.Equal(t, uint(0), uti.RuneCount(""))
+}
+
+func TestTrimKeepCase(t *tes.T) {
+	ass.Equal(t, "foo", uti.TrimPrefixKeepCase("getFoo", "get"))
+	ass.Equal(t, "Foo", uti.TrimPrefixKeepCase("GetFoo", "Get"))
+	ass.Equal(t, "fooBar", uti.TrimPrefixKeepCase("isFooBar", "is"))
+	ass.Equal(t, "éclair", uti.TrimPrefixKeepCase("getÉclair", "get"))
+	ass.Equal(t, "getter", uti.TrimPrefixKeepCase("getter", "get"))
+	ass.Equal(t, "get", uti.TrimPrefixKeepCase("get", "get"))
+	ass.Equal(t, "setFoo", uti.TrimPrefixKeepCase("setFoo", "get"))
+	ass.Equal(t, "getFoo", uti.TrimPrefixKeepCase("getFoo", ""))
+
+	ass.Equal(t, "foo", uti.TrimSuffixKeepCase("fooBar", "Bar"))
+	ass.Equal(t, "Foo", uti.TrimSuffixKeepCase("FooBarLike", "BarLike"))
+	ass.Equal(t, "crowbar", uti.TrimSuffixKeepCase("crowbar", "bar"))
+	ass.Equal(t, "Bar", uti.TrimSuffixKeepCase("Bar", "Bar"))
+	ass.Equal(t, "fooBar", uti.TrimSuffixKeepCase("fooBar", "Baz"))
+}
+
+func TestContainsFold(t *tes.T) {
+	ass.True(t, uti.ContainsFold("The Quick Brown Fox", "quick BROWN"))
+	ass.True(t, uti.ContainsFold("hello", "HELLO"))
+	ass.True(t, uti.ContainsFold("anything", ""))
+	ass.False(t, uti.ContainsFold("hello", "world"))
+	ass.False(t, uti.ContainsFold("hell", "hello"))
+	ass.True(t, uti.ContainsFold("Größe", "GRÖẞE"))
+	ass.True(t, uti.ContainsFold("ΣΊΣΥΦΟΣ", "σίσυφος"))
+	ass.True(t, uti.ContainsFold("Temperature: 5\u212A", "5k"))
+	ass.True(t, uti.ContainsFold("Привет Мир", "мир"))
+}
+
+func TestCapitalization(t *tes.T) {
+	var input = "the QUICK brown  fox"
+	ass.Equal(t, "The Quick Brown  Fox", uti.CapitalizeWords(input))
+	ass.Equal(t, "The quick brown  fox", uti.MakeSentenceCase(input))
+	ass.Equal(t, "\"Hello,\" she said", uti.MakeSentenceCase("\"HELLO,\" SHE SAID"))
+	ass.Equal(t, "", uti.CapitalizeWords(""))
+	ass.Equal(t, "", uti.MakeSentenceCase(""))
+}
+
+func TestConvertCase(t *tes.T) {
+	var allCaps = "HELLO_WORLD"
+	ass.Equal(t, "helloWorld", uti.ConvertCase(allCaps, "allcaps", "camel"))
+	ass.Equal(t, "hello-world", uti.ConvertCase(allCaps, "allcaps", "snake"))
+	ass.Equal(t, "hello.world", uti.ConvertCase(allCaps, "allcaps", "dot"))
+	ass.Equal(t, "HelloWorld", uti.ConvertCase(allCaps, "", "upper"))
+	ass.Equal(t, "Hello World", uti.ConvertCase("helloWorld", "", "title"))
+	ass.Equal(t, "HELLO_WORLD", uti.ConvertCase("hello.world", "dot", "allcaps"))
+	ass.Panics(t, func() { uti.ConvertCase(allCaps, "allcaps", "kebab") })
+}
+
+func TestDivMod(t *tes.T) {
+	var quotient, remainder, ok = uti.DivMod(7, 2)
+	ass.True(t, ok)
+	ass.Equal(t, 3, quotient)
+	ass.Equal(t, 1, remainder)
+
+	quotient, remainder, ok = uti.DivMod(-7, 2)
+	ass.True(t, ok)
+	ass.Equal(t, -3, quotient)
+	ass.Equal(t, -1, remainder)
+
+	quotient, remainder, ok = uti.DivMod(7, -2)
+	ass.True(t, ok)
+	ass.Equal(t, -3, quotient)
+	ass.Equal(t, 1, remainder)
+
+	quotient, remainder, ok = uti.DivMod(7, 0)
+	ass.False(t, ok)
+	ass.Equal(t, 0, quotient)
+	ass.Equal(t, 0, remainder)
+}
+
+func TestParseDurationOrDefault(t *tes.T) {
+	var fallback = 5 * tim.Second
+	ass.Equal(t, 90*tim.Minute, uti.ParseDurationOrDefault("1h30m", fallback))
+	ass.Equal(t, 250*tim.Millisecond, uti.ParseDurationOrDefault("250ms", fallback))
+	ass.Equal(t, tim.Duration(0), uti.ParseDurationOrDefault("0", fallback))
+	ass.Equal(t, fallback, uti.ParseDurationOrDefault("soon", fallback))
+	ass.Equal(t, fallback, uti.ParseDurationOrDefault("10", fallback))
+	ass.Equal(t, fallback, uti.ParseDurationOrDefault("", fallback))
+}
+
+func TestRandomHex(t *tes.T) {
+	var size uint = 16
+	var first = uti.RandomHex(size)
+	ass.Equal(t, int(2*size), len(first))
+	for _, character := range first {
+		ass.Contains(t, "0123456789abcdef", string(character))
+	}
+	var second = uti.RandomHex(size)
+	ass.NotEqual(t, first, second)
+	ass.Equal(t, "", uti.RandomHex(0))
+}
+
+func TestLazy(t *tes.T) {
+	var mutex syn.Mutex
+	var calls int
+	var value = uti.Lazy(func() []int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		calls++
+		return []int{1, 2, 3}
+	})
+	var group syn.WaitGroup
+	for index := 0; index < 10; index++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			ass.Equal(t, []int{1, 2, 3}, value())
+		}()
+	}
+	group.Wait()
+	ass.Equal(t, []int{1, 2, 3}, value())
+	ass.Equal(t, 1, calls)
+
+	calls = 0
+	var failing = uti.Lazy(func() int {
+		calls++
+		panic("boom")
+	})
+	ass.PanicsWithValue(t, "boom", func() { failing() })
+	ass.PanicsWithValue(t, "boom", func() { failing() })
+	ass.Equal(t, 1, calls)
+}
+
+func TestMemoize(t *tes.T) {
+	var calls int
+	var square = uti.Memoize(func(value int) int {
+		calls++
+		return value * value
+	})
+	ass.Equal(t, 9, square(3))
+	ass.Equal(t, 9, square(3))
+	ass.Equal(t, 16, square(4))
+	ass.Equal(t, 2, calls)
+
+	calls = 0
+	var sum = uti.MemoizeAny(func(values []int) int {
+		calls++
+		var total int
+		for _, value := range values {
+			total += value
+		}
+		return total
+	})
+	ass.Equal(t, 6, sum([]int{1, 2, 3}))
+	ass.Equal(t, 6, sum([]int{1, 2, 3}))
+	ass.Equal(t, 5, sum([]int{2, 3}))
+	ass.Equal(t, 2, calls)
+}
+
+func TestTap(t *tes.T) {
+	var logged []string
+	var log = func(value string) {
+		logged = append(logged, value)
+	}
+	var result = sts.ToUpper(uti.Tap(sts.TrimSpace("  hello  "), log))
+	ass.Equal(t, "HELLO", result)
+	ass.Equal(t, []string{"hello"}, logged)
+}
+
+func TestMemoizeRecursive(t *tes.T) {
+	var calls int
+	var fibonacci func(uint) uint
+	fibonacci = uti.Memoize(func(index uint) uint {
+		calls++
+		if index < 2 {
+			return index
+		}
+		return fibonacci(index-1) + fibonacci(index-2)
+	})
+	ass.Equal(t, uint(12586269025), fibonacci(50))
+	ass.Equal(t, 51, calls)
+
+	var lengths func([]int) int
+	lengths = uti.MemoizeAny(func(values []int) int {
+		if len(values) == 0 {
+			return 0
+		}
+		return 1 + lengths(values[1:])
+	})
+	ass.Equal(t, 3, lengths([]int{1, 2, 3}))
+}
+
+func TestMemoizePanics(t *tes.T) {
+	var calls int
+	var divide = uti.Memoize(func(divisor int) int {
+		calls++
+		if divisor == 0 {
+			panic("boom")
+		}
+		return 12 / divisor
+	})
+	ass.PanicsWithValue(t, "boom", func() { divide(0) })
+	ass.PanicsWithValue(t, "boom", func() { divide(0) })
+	ass.Equal(t, 4, divide(3))
+	ass.Equal(t, 2, calls)
+
+	var head = uti.MemoizeAny(func(values []int) int {
+		if len(values) == 0 {
+			panic("boom")
+		}
+		return values[0]
+	})
+	ass.PanicsWithValue(t, "boom", func() { head([]int{}) })
+	ass.PanicsWithValue(t, "boom", func() { head([]int{}) })
+	ass.Equal(t, 1, head([]int{1}))
+}
+
+type Hidden struct {
+	value int
+}
+
+func TestMemoizeAnyExactly(t *tes.T) {
+	var identity = uti.MemoizeAny(func(hidden Hidden) int {
+		return hidden.value
+	})
+	ass.Panics(t, func() { identity(Hidden{1}) })
+
+	var deep = uti.MemoizeAny(func(values [][][][][][][][][]int) int {
+		return len(values)
+	})
+	ass.Panics(t, func() { deep([][][][][][][][][]int{{{{{{{{{1}}}}}}}}}) })
+
+	var first = uti.MemoizeAny(func(values []string) string {
+		return values[0]
+	})
+	var typeName = uti.MemoizeAny(func(value any) string {
+		return fmt.Sprintf("%T", value)
+	})
+	ass.Equal(t, "int", typeName(5))
+	ass.Equal(t, "int64", typeName(int64(5)))
+	ass.Equal(t, "int8", typeName(int8(5)))
+	ass.Equal(t, "<nil>", typeName(nil))
+
+	ass.Equal(t, "wait...", first([]string{"wait..."}))
+	ass.Equal(t, "<private>", first([]string{"<private>"}))
+}
+
+func TestErrors(t *tes.T) {
+	var value = uti.Must(5, nil)
+	ass.Equal(t, 5, value)
+	var err = errors.New("failed")
+	ass.PanicsWithError(t, "failed", func() { uti.Must(5, err) })
+
+	ass.Nil(t, uti.Recover(func() {}))
+	ass.Equal(t, err, uti.Recover(func() { panic(err) }))
+	err = uti.Recover(func() { panic("oops") })
+	ass.EqualError(t, err, "oops")
+	err = uti.Recover(func() { uti.Must(0, errors.New("failed")) })
+	ass.EqualError(t, err, "failed")
+}
+
 type Interface interface {
 	DoNothing()
 }
@@ -403,6 +2114,104 @@ type Class struct{}
 func (v *Class) DoNothing() {
 }
 
+func TestRetry(t *tes.T) {
+	var count int
+	var flaky = func() {
+		count++
+		if count < 3 {
+			panic(fmt.Sprintf("Attempt %v failed.", count))
+		}
+	}
+	ass.Nil(t, uti.Retry(3, flaky))
+	ass.Equal(t, 3, count)
+
+	count = 0
+	var err = uti.Retry(2, flaky)
+	ass.EqualError(t, err, "Attempt 2 failed.")
+	ass.Equal(t, 2, count)
+
+	ass.Panics(t, func() { uti.Retry(0, flaky) })
+}
+
+func TestAsArray(t *tes.T) {
+	var value any = []int{1, 2, 3}
+	ass.Equal(t, []any{1, 2, 3}, uti.AsArray(value))
+	value = [2]string{"alpha", "beta"}
+	ass.Equal(t, []any{"alpha", "beta"}, uti.AsArray(value))
+	ass.Equal(t, []any{}, uti.AsArray([]bool{}))
+	ass.Panics(t, func() { uti.AsArray(5) })
+	ass.Panics(t, func() { uti.AsArray(nil) })
+}
+
+func TestAsString(t *tes.T) {
+	ass.Equal(t, "Hello World!", uti.AsString(stringHello))
+	ass.Equal(t, "13", uti.AsString(int13))
+	ass.Equal(t, "16", uti.AsString(byte16))
+	ass.Equal(t, "Ѐ", uti.AsString(rune1024))
+	ass.Equal(t, "1.23E+10", uti.AsString(float))
+	ass.Equal(t, "true", uti.AsString(booleanTrue))
+	ass.Equal(t, "42", uti.AsString(Integer(42)))
+	var triangle = Triangle{
+		X: 3.0,
+		Y: 4.0,
+		r: 5.0,
+	}
+	ass.Equal(t, uti.Format(triangle), uti.AsString(triangle))
+	ass.Equal(t, "<nil>", uti.AsString(nil))
+}
+
+func TestCoalesce(t *tes.T) {
+	var first *int
+	var second *int
+	var integer = 5
+	var third = &integer
+	ass.Equal(t, third, uti.Coalesce(first, second, third))
+	ass.Nil(t, uti.Coalesce(first, second))
+	ass.Equal(t, "default", uti.Coalesce("", "default", "other"))
+	ass.Equal(t, []int{}, uti.Coalesce(nil, []int{}, []int{1}))
+	ass.Equal(t, "", uti.Coalesce[string]())
+}
+
+func TestDefault(t *tes.T) {
+	ass.Equal(t, "fallback", uti.Default("", "fallback"))
+	ass.Equal(t, "value", uti.Default("value", "fallback"))
+	var populated = []int{1, 2}
+	ass.Equal(t, populated, uti.Default(populated, []int{3}))
+	var missing []int
+	ass.Equal(t, []int{3}, uti.Default(missing, []int{3}))
+	ass.Equal(t, 0, uti.Default(0, 5))
+}
+
+func TestEqual(t *tes.T) {
+	ass.True(t, uti.Equal([]int{1, 2, 3}, []int{1, 2, 3}))
+	ass.False(t, uti.Equal([]int{1, 2, 3}, []int{1, 3, 2}))
+	ass.False(t, uti.Equal([]int{1, 2}, []int{1, 2, 3}))
+	ass.True(t, uti.Equal([][]string{{"a"}, {}}, [][]string{{"a"}, {}}))
+
+	var first = map[string][]int{"one": {1}, "two": {2, 2}}
+	var second = map[string][]int{"two": {2, 2}, "one": {1}}
+	ass.True(t, uti.Equal(first, second))
+	second["two"] = []int{2}
+	ass.False(t, uti.Equal(first, second))
+	delete(second, "two")
+	second["three"] = []int{2, 2}
+	ass.False(t, uti.Equal(first, second))
+
+	ass.True(t, uti.Equal(5, 5))
+	ass.False(t, uti.Equal(5, 6))
+	ass.False(t, uti.Equal(5, int64(5)))
+	ass.True(t, uti.Equal("hello", "hello"))
+	ass.True(t, uti.Equal(Triangle{X: 1}, Triangle{X: 1}))
+	ass.False(t, uti.Equal(tim.Now(), tim.Unix(0, 0)))
+	var instant = tim.Unix(1, 0)
+	ass.True(t, uti.Equal(instant, instant))
+	ass.False(t, uti.Equal(struct{ a int }{1}, struct{ a int }{2}))
+	ass.True(t, uti.Equal(struct{ a int }{1}, struct{ a int }{1}))
+	ass.False(t, uti.Equal([]int{1}, map[int]int{0: 1}))
+	ass.True(t, uti.Equal(nil, nil))
+	ass.False(t, uti.Equal(nil, 0))
+}
+
 func TestReflection(t *tes.T) {
 	var emptyString string
 	ass.True(t, uti.IsUndefined(emptyString))